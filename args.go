@@ -0,0 +1,161 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// WithOmitDefaults makes ToArgs omit flags which values are equal to their defaults.
+func WithOmitDefaults() OptFunc { return func(opt *opts) { opt.omitDefaults = true } }
+
+// ToArgs returns command line flags that, when parsed by application with the given struct flagarized, fill the struct
+// with values equal to the current ones. Flags of fields with zero values that are equal to their defaults are omitted
+// (all flags equal to their defaults are omitted with WithOmitDefaults option).
+// Zero values that cannot be passed as flag (e.g nil *url.URL or empty slice) are omitted as well, so defaults are used
+// for them. Error is returned if such field has different default (e.g empty slice with `default=a`), as the struct
+// would not be reproduced. Nil maps are reproduced as empty maps, the same as Flagarize initializes them.
+// Fields with custom Flagarizer (e.g PathOrContent) are skipped, as flags they register are unknown.
+// Flags of commands (see cmd struct tag key) are skipped, use ToArgs method of Flagarized to include the selected one.
+// Positional arguments (see arg struct tag key) are placed after flags in the declaration order. Trailing arguments
+// are omitted the same way as flags.
+// The same options as passed to Flagarize should be passed, so flag names are the same.
+func ToArgs(s interface{}, o ...OptFunc) ([]string, error) {
+	return toArgs(s, nil, o...)
+}
+
+// ToArgs returns command line flags of the flagarized struct the same as ToArgs function does. If the struct has
+// commands and it was parsed, the selected command is the first argument followed by flags of the selected command
+// (and its parents) only.
+func (f *Flagarized) ToArgs(o ...OptFunc) ([]string, error) {
+	// Flags of commands are skipped if no command was selected.
+	c, _ := f.commands.get()
+	return toArgs(f.s, c, o...)
+}
+
+// toArgs returns command line flags of the struct with the given selected command, nil if none was selected.
+func toArgs(s interface{}, c *command, o ...OptFunc) ([]string, error) {
+	v, err := structValue(s)
+	if err != nil {
+		return nil, err
+	}
+	opt := opts{elemSep: "|"}.apply(o...)
+
+	var args []string
+	opt.commandSelected = func(FieldPath) bool { return false }
+	if c != nil {
+		args = strings.Fields(c.Name)
+		opt.commandSelected = func(p FieldPath) bool { return len(p) <= len(c.Path) && equalStrings(p, c.Path[:len(p)]) }
+	}
+	fields, err := dumpFields(v, opt, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "flagarize")
+	}
+
+	var (
+		positional [][]string
+		// used is the number of positional arguments that have to be passed, as the next ones can be omitted.
+		used int
+	)
+	for _, f := range fields {
+		if implementsFlagarizer(f.value.Type()) && opt.customValue(f.value.Type()) == nil {
+			continue
+		}
+		def, err := defaultValues(f.tag, f.value.Type(), f.path, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "flagarize")
+		}
+		omit := equalStrings(f.values, def) && (opt.omitDefaults || isZeroValue(f.value))
+		if isZeroValue(f.value) && !hasZeroFlagValue(f.value.Kind()) {
+			// Zero value cannot be passed as flag, so default is used.
+			if !omit {
+				return nil, errors.Errorf("flagarize: %s: zero value cannot be passed as flag, so default %q would be used instead", f.path, def)
+			}
+		}
+		if f.tag.Arg {
+			// Positional arguments can be omitted only at the end.
+			positional = append(positional, f.args())
+			if !omit {
+				used = len(positional)
+			}
+			continue
+		}
+		if omit {
+			continue
+		}
+		args = append(args, f.args()...)
+	}
+
+	var values []string
+	for _, p := range positional[:used] {
+		values = append(values, p...)
+	}
+	for _, v := range values {
+		if strings.HasPrefix(v, "-") {
+			// Mark the end of flags, so values are not parsed as flags.
+			args = append(args, "--")
+			break
+		}
+	}
+	args = append(args, values...)
+	return args, nil
+}
+
+// defaultValues returns rendered value of the given type that flag registered with the given tag has if not set.
+func defaultValues(tag *Tag, typ reflect.Type, path FieldPath, o opts) ([]string, error) {
+	v := reflect.New(typ).Elem()
+	if tag.DefaultValue != "" || len(tag.defaultValues) > 0 {
+		t := *tag
+		t.Required = false
+		t.EnvName = ""
+
+		app := kingpin.New(path.String(), "")
+		ok, err := registerCustomValue(app, &t, v, o)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if err := registerBuiltinValue(t.Flag(app), v); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := app.Parse(nil); err != nil {
+			return nil, errors.Wrapf(err, "parse default of %s", path)
+		}
+	}
+
+	ret := o.formatValue(v)
+	if k := v.Kind(); len(ret) == 0 && k != reflect.Slice && k != reflect.Map {
+		return formatZeroValue(v), nil
+	}
+	return ret, nil
+}
+
+// hasZeroFlagValue returns true if zero value of the given kind can be passed as flag value.
+func hasZeroFlagValue(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
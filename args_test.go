@@ -0,0 +1,198 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type allTypesConfig struct {
+	String    string                   `flagarize:"help=help"`
+	Bool      bool                     `flagarize:"help=help"`
+	BoolDef   bool                     `flagarize:"help=help|default=true"`
+	Uint      uint                     `flagarize:"help=help"`
+	Uint8     uint8                    `flagarize:"help=help"`
+	Uint16    uint16                   `flagarize:"help=help"`
+	Uint32    uint32                   `flagarize:"help=help"`
+	Uint64    uint64                   `flagarize:"help=help"`
+	Int       int                      `flagarize:"help=help|default=15"`
+	Int8      int8                     `flagarize:"help=help"`
+	Int16     int16                    `flagarize:"help=help"`
+	Int32     int32                    `flagarize:"help=help"`
+	Int64     int64                    `flagarize:"help=help"`
+	Float32   float32                  `flagarize:"help=help"`
+	Float64   float64                  `flagarize:"help=help"`
+	Duration  time.Duration            `flagarize:"help=help|default=1m"`
+	IP        net.IP                   `flagarize:"help=help"`
+	Bytes     units.Base2Bytes         `flagarize:"help=help"`
+	TCPAddr   *net.TCPAddr             `flagarize:"help=help"`
+	URL       *url.URL                 `flagarize:"help=help"`
+	Bools     []bool                   `flagarize:"help=help"`
+	Strings   []string                 `flagarize:"help=help|default=a"`
+	Ints      []int                    `flagarize:"help=help"`
+	Int8s     []int8                   `flagarize:"help=help"`
+	Int16s    []int16                  `flagarize:"help=help"`
+	Int32s    []int32                  `flagarize:"help=help"`
+	Int64s    []int64                  `flagarize:"help=help"`
+	Uints     []uint                   `flagarize:"help=help"`
+	Uint8s    []uint8                  `flagarize:"help=help"`
+	Uint16s   []uint16                 `flagarize:"help=help"`
+	Uint32s   []uint32                 `flagarize:"help=help"`
+	Uint64s   []uint64                 `flagarize:"help=help"`
+	Float32s  []float32                `flagarize:"help=help"`
+	Float64s  []float64                `flagarize:"help=help"`
+	Durations []time.Duration          `flagarize:"help=help"`
+	IPs       []net.IP                 `flagarize:"help=help"`
+	TCPAddrs  []*net.TCPAddr           `flagarize:"help=help"`
+	URLs      []*url.URL               `flagarize:"help=help"`
+	Map       map[string]string        `flagarize:"help=help"`
+	Regexp    flagarize.Regexp         `flagarize:"help=help"`
+	Anchored  flagarize.AnchoredRegexp `flagarize:"help=help"`
+	TimeOrDur flagarize.TimeOrDuration `flagarize:"help=help"`
+	Since     flagarize.TimeOrDuration `flagarize:"help=help|default=-1h"`
+}
+
+const randChars = "abcXYZ019 =,|-_.:/\"'\n\t"
+
+func randString(r *rand.Rand) string {
+	b := make([]byte, r.Intn(10))
+	for i := range b {
+		b[i] = randChars[r.Intn(len(randChars))]
+	}
+	return string(b)
+}
+
+// randConfig returns config with random values. Values are zero with some probability.
+func randConfig(t *testing.T, r *rand.Rand) *allTypesConfig {
+	n := func() bool { return r.Intn(3) == 0 }
+	c := &allTypesConfig{
+		String:  randString(r),
+		Bool:    r.Intn(2) == 0,
+		BoolDef: r.Intn(2) == 0,
+		Uint:    uint(r.Uint64()),
+		Uint8:   uint8(r.Uint32()),
+		Uint16:  uint16(r.Uint32()),
+		Uint32:  r.Uint32(),
+		Uint64:  r.Uint64(),
+		// Kingpin parses int via float64, so bigger ints lose precision.
+		Int:      int(r.Int63n(1<<53)) - r.Intn(100),
+		Int8:     int8(r.Int()),
+		Int16:    int16(r.Int()),
+		Int32:    r.Int31() - r.Int31(),
+		Int64:    r.Int63() - r.Int63(),
+		Float32:  r.Float32() * float32(r.Intn(1000)-500),
+		Float64:  r.NormFloat64() * 1e10,
+		Duration: time.Duration(r.Int63() - r.Int63()),
+		Bytes:    units.Base2Bytes(r.Intn(1 << 30)),
+		Map:      map[string]string{},
+	}
+	if n() {
+		c.IP = net.IPv4(byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+		c.TCPAddr = &net.TCPAddr{IP: c.IP, Port: r.Intn(65536)}
+		c.URL = &url.URL{Scheme: "http", Host: "localhost:80", Path: "/path"}
+	}
+	for i := 0; i < r.Intn(3); i++ {
+		c.Bools = append(c.Bools, r.Intn(2) == 0)
+		c.Strings = append(c.Strings, randString(r))
+		c.Ints = append(c.Ints, int(r.Int63n(1<<53)-r.Int63n(1<<53)))
+		c.Int8s = append(c.Int8s, int8(r.Int()))
+		c.Int16s = append(c.Int16s, int16(r.Int()))
+		c.Int32s = append(c.Int32s, r.Int31()-r.Int31())
+		c.Int64s = append(c.Int64s, r.Int63()-r.Int63())
+		c.Uints = append(c.Uints, uint(r.Uint64()))
+		c.Uint8s = append(c.Uint8s, uint8(r.Uint32()))
+		c.Uint16s = append(c.Uint16s, uint16(r.Uint32()))
+		c.Uint32s = append(c.Uint32s, r.Uint32())
+		c.Uint64s = append(c.Uint64s, r.Uint64())
+		c.Float32s = append(c.Float32s, r.Float32())
+		c.Float64s = append(c.Float64s, r.ExpFloat64())
+		c.Durations = append(c.Durations, time.Duration(r.Int63()))
+		c.IPs = append(c.IPs, net.ParseIP("2001:db8::68"))
+		c.TCPAddrs = append(c.TCPAddrs, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: r.Intn(65536)})
+		c.URLs = append(c.URLs, &url.URL{Scheme: "https", Host: "example.com", RawQuery: "a=b"})
+		// Keys cannot contain "=" or ":" as kingpin separates key and value with any of them.
+		c.Map[strings.NewReplacer("=", "", ":", "").Replace(randString(r))+"k"] = randString(r)
+	}
+	if n() {
+		testutil.Ok(t, c.Regexp.Set("a+(b|c)*"))
+		testutil.Ok(t, c.Anchored.Set("[a-z]+"))
+	}
+	switch r.Intn(3) {
+	case 0:
+		tm := time.Unix(r.Int63n(1e10), r.Int63n(1e9)).UTC()
+		c.TimeOrDur.Time = &tm
+	case 1:
+		d := time.Duration(r.Int63() - r.Int63()).Round(time.Millisecond)
+		c.TimeOrDur.Dur = &d
+	}
+	d := time.Duration(-r.Int63n(1e12))
+	c.Since.Dur = &d
+	if len(c.Strings) == 0 {
+		// Empty slice cannot be passed as flag, so default would be used (see TestToArgs).
+		c.Strings = []string{randString(r)}
+	}
+	return c
+}
+
+func TestToArgs_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(2020))
+	for i := 0; i < 200; i++ {
+		exp := randConfig(t, r)
+		if i == 0 {
+			// Test zero values as well, except those that cannot be passed as flags and have different defaults.
+			since := -time.Hour
+			exp = &allTypesConfig{Strings: []string{"a"}, Map: map[string]string{}, Since: flagarize.TimeOrDuration{Dur: &since}}
+		}
+
+		for _, o := range [][]flagarize.OptFunc{nil, {flagarize.WithOmitDefaults()}} {
+			args, err := flagarize.ToArgs(exp, o...)
+			testutil.Ok(t, err)
+
+			app := newTestKingpin(t)
+			got := &allTypesConfig{}
+			testutil.Ok(t, flagarize.Flagarize(app, got))
+			_, err = app.Parse(args)
+			testutil.Assert(t, err == nil, "args: %q: %v", args, err)
+			testutil.Equals(t, exp, got, "args: %q", args)
+		}
+	}
+}
+
+func TestToArgs(t *testing.T) {
+	type config struct {
+		Name  string   `flagarize:"name=name|help=Name.|default=a"`
+		Port  int      `flagarize:"name=port|help=Port.|default=80"`
+		Debug bool     `flagarize:"name=debug|help=Debug.|default=true"`
+		Peers []string `flagarize:"name=peer|help=Peers."`
+		Pass  string   `flagarize:"name=pass|help=Pass.|secret=true"`
+	}
+	cfg := &config{Name: "a", Port: 0, Debug: false, Peers: []string{"p1", "p2"}, Pass: "secret"}
+
+	args, err := flagarize.ToArgs(cfg)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"--name=a", "--port=0", "--no-debug", "--peer=p1", "--peer=p2", "--pass=secret"}, args)
+
+	args, err = flagarize.ToArgs(cfg, flagarize.WithOmitDefaults())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"--port=0", "--no-debug", "--peer=p1", "--peer=p2", "--pass=secret"}, args)
+
+	t.Run("zero value with default", func(t *testing.T) {
+		_, err := flagarize.ToArgs(&allTypesConfig{})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, `flagarize: Strings: zero value cannot be passed as flag, so default ["a"] would be used instead`, err.Error())
+
+		_, err = flagarize.ToArgs(&allTypesConfig{Strings: []string{"a"}})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, `flagarize: Since: zero value cannot be passed as flag, so default ["-1h0m0s"] would be used instead`, err.Error())
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Command flagarize-gen generates plain kingpin registration code for structs with `flagarize:"..."` struct tags,
+// so struct tag errors are reported on go generate instead of on program start. For example:
+//
+//	//go:generate go run github.com/bwplotka/flagarize/cmd/flagarize-gen --type=Config
+//
+// generates config_flagarize.go file with FlagarizeConfig(r flagarize.Registry, c *Config) error function.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/flagarize/gen"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func main() {
+	a := kingpin.New(filepath.Base(os.Args[0]), "Generates kingpin registration code for structs with flagarize struct tags.")
+	types := a.Flag("type", "Comma separated names of struct types to generate registration for.").Required().String()
+	dir := a.Flag("dir", "Directory of the Go package with the types.").Default(".").String()
+	output := a.Flag("output", "Output file name; default <dir>/<type>_flagarize.go of the first type.").String()
+	if _, err := a.Parse(os.Args[1:]); err != nil {
+		a.FatalUsage("%s", err)
+	}
+
+	names := strings.Split(*types, ",")
+	var b bytes.Buffer
+	if err := gen.Generate(&b, *dir, names...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		*output = filepath.Join(*dir, strings.ToLower(names[0])+"_flagarize.go")
+	}
+	if err := ioutil.WriteFile(*output, b.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
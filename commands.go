@@ -0,0 +1,158 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Runner can be implemented by command structs (nested structs with cmd=<name> struct tag) to run the command.
+// See Run.
+type Runner interface {
+	// Run runs the command. It is invoked after parse, so all flags are set.
+	Run(ctx context.Context) error
+}
+
+var runnerType = reflect.TypeOf((*Runner)(nil)).Elem()
+
+// Command is a command registered from the nested struct field with cmd=<name> struct tag.
+type Command struct {
+	// Path is the path of the command struct field e.g FieldPath{"Tools", "Compact"}.
+	Path FieldPath
+	// Name is the full command as passed in the command line e.g "tools compact".
+	Name string
+}
+
+// command is a registered command with its struct value.
+type command struct {
+	Command
+	clause *kingpin.CmdClause
+	value  reflect.Value
+}
+
+// parseCommand registers command from the nested struct with cmd=<name> struct tag. Flags from the nested struct
+// are registered in the command, so they are parsed and checked only if the command is selected.
+func parseCommand(r KingpinRegistry, value reflect.Value, path FieldPath, pos FieldPos, n *nestedTag, o opts) error {
+	clause := r.Command(n.cmd, n.cmdHelp)
+	if n.hidden {
+		clause.Hidden()
+	}
+	*o.commands = append(*o.commands, &command{
+		Command: Command{Path: path, Name: clause.FullCommand()},
+		clause:  clause,
+		value:   value,
+	})
+
+	o.cmd = clause
+	o.constraints = newConstraints()
+	o.parents = append(o.parents[:len(o.parents):len(o.parents)], r)
+	if err := parseStruct(clause, value, path, n, o); err != nil {
+		return err
+	}
+	if err := o.constraints.install(clause, o.parents); err != nil {
+		return withFieldPos(err, pos)
+	}
+	return nil
+}
+
+// selectedCommands returns function reporting if command under the given path was selected in the parse context.
+func selectedCommands(ctx *kingpin.ParseContext, commands []*command) func(FieldPath) bool {
+	selected := map[string]bool{}
+	for _, e := range ctx.Elements {
+		c, ok := e.Clause.(*kingpin.CmdClause)
+		if !ok {
+			continue
+		}
+		for _, cmd := range commands {
+			if cmd.clause == c {
+				selected[cmd.Path.String()] = true
+			}
+		}
+	}
+	return func(path FieldPath) bool { return selected[path.String()] }
+}
+
+// commandTracker tracks command selected on parse for the single flagarized struct.
+type commandTracker struct {
+	mtx      sync.Mutex
+	commands []*command
+	parsed   bool
+	selected *command
+	err      error
+}
+
+// install registers post-parse action that records selected command.
+func (t *commandTracker) install(r KingpinRegistry, commands []*command) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	t.commands = commands
+	return addPostParseAction(r, func(ctx *kingpin.ParseContext) error {
+		t.mtx.Lock()
+		defer t.mtx.Unlock()
+
+		t.parsed, t.selected, t.err = true, nil, nil
+		if ctx.SelectedCommand == nil {
+			return nil
+		}
+		for _, c := range t.commands {
+			if c.clause == ctx.SelectedCommand {
+				t.selected = c
+				return nil
+			}
+		}
+		t.err = errors.Errorf("flagarize: selected command %q was not flagarized", ctx.SelectedCommand.FullCommand())
+		return nil
+	})
+}
+
+func (t *commandTracker) get() (*command, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	switch {
+	case len(t.commands) == 0:
+		return nil, errors.New("flagarize: object has no commands flagarized")
+	case !t.parsed:
+		return nil, errors.New("flagarize: flags were not parsed yet")
+	case t.err != nil:
+		return nil, t.err
+	case t.selected == nil:
+		return nil, errors.New("flagarize: no command was selected")
+	}
+	return t.selected, nil
+}
+
+// SelectedCommand returns the command selected on parse, registered from the nested struct field with cmd=<name>
+// struct tag. For nested commands, the most nested one is returned.
+func (f *Flagarized) SelectedCommand() (Command, error) {
+	c, err := f.commands.get()
+	if err != nil {
+		return Command{}, err
+	}
+	return c.Command, nil
+}
+
+// Run invokes Run method of the command struct selected on parse (see SelectedCommand). The command struct (or pointer
+// to it) has to implement Runner.
+func (f *Flagarized) Run(ctx context.Context) error {
+	c, err := f.commands.get()
+	if err != nil {
+		return err
+	}
+
+	v := c.value
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(runnerType) {
+		v = v.Addr()
+	}
+	if !v.Type().Implements(runnerType) || !v.CanInterface() {
+		return errors.Errorf("flagarize: command %q (%s) does not implement Run(context.Context) error", c.Name, c.Path)
+	}
+	return v.Interface().(Runner).Run(ctx)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+	"github.com/pkg/errors"
+)
+
+type compactCmd struct {
+	Concurrency int  `flagarize:"name=concurrency|help=Concurrency.|default=1|min=1"`
+	DryRun      bool `flagarize:"name=dry-run|help=Dry run."`
+
+	ran bool
+}
+
+func (c *compactCmd) Run(context.Context) error {
+	c.ran = true
+	return nil
+}
+
+func (c *compactCmd) Validate() error {
+	if c.Concurrency > 10 && !c.DryRun {
+		return errors.New("too much")
+	}
+	return nil
+}
+
+type inspectCmd struct {
+	Selector string `flagarize:"name=selector|help=Selector.|required=true"`
+}
+
+type cmdConfig struct {
+	Debug bool `flagarize:"name=debug|help=Debug."`
+
+	Tools struct {
+		Compact compactCmd `flagarize:"cmd=compact|help=Compact blocks."`
+		Inspect inspectCmd `flagarize:"cmd=inspect|help=Inspect blocks."`
+	} `flagarize:"cmd=tools|help=Tools."`
+}
+
+func TestFlagarize_Commands(t *testing.T) {
+	t.Run("selected command runs", func(t *testing.T) {
+		app := newTestKingpin(t)
+		cfg := &cmdConfig{}
+		f, err := flagarize.New(app, cfg, flagarize.WithValidation())
+		testutil.Ok(t, err)
+
+		// Required flag of not selected command is not required and validation of it is not invoked.
+		cmd, err := app.Parse([]string{"tools", "compact", "--concurrency=20", "--dry-run", "--debug"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, "tools compact", cmd)
+		testutil.Equals(t, true, cfg.Debug)
+		testutil.Equals(t, 20, cfg.Tools.Compact.Concurrency)
+
+		c, err := f.SelectedCommand()
+		testutil.Ok(t, err)
+		testutil.Equals(t, flagarize.Command{Path: flagarize.FieldPath{"Tools", "Compact"}, Name: "tools compact"}, c)
+
+		testutil.Ok(t, f.Run(context.Background()))
+		testutil.Equals(t, true, cfg.Tools.Compact.ran)
+
+		args, err := f.ToArgs()
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{"tools", "compact", "--debug", "--concurrency=20", "--dry-run"}, args)
+		args, err = flagarize.ToArgs(cfg)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{"--debug"}, args)
+
+		// Flagarizing the same struct again does not affect the handle.
+		other, err := flagarize.New(newTestKingpin(t), cfg)
+		testutil.Ok(t, err)
+		_, err = other.SelectedCommand()
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: flags were not parsed yet", err.Error())
+		c, err = f.SelectedCommand()
+		testutil.Ok(t, err)
+		testutil.Equals(t, "tools compact", c.Name)
+	})
+	t.Run("command constraints and validation", func(t *testing.T) {
+		app := newTestKingpin(t)
+		cfg := &cmdConfig{}
+		testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithValidation()))
+
+		_, err := app.Parse([]string{"tools", "compact", "--concurrency=0"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "invalid value \"0\" from flag --concurrency: has to be greater than or equal to 1", err.Error())
+
+		_, err = app.Parse([]string{"tools", "compact", "--concurrency=20"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "Tools.Compact: too much", err.Error())
+	})
+	t.Run("command without Run", func(t *testing.T) {
+		app := newTestKingpin(t)
+		f, err := flagarize.New(app, &cmdConfig{})
+		testutil.Ok(t, err)
+
+		_, err = f.SelectedCommand()
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: flags were not parsed yet", err.Error())
+
+		_, err = app.Parse([]string{"tools", "inspect"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "required flag --selector not provided", err.Error())
+
+		_, err = app.Parse([]string{"tools", "inspect", "--selector=a"})
+		testutil.Ok(t, err)
+		err = f.Run(context.Background())
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: command \"tools inspect\" (Tools.Inspect) does not implement Run(context.Context) error", err.Error())
+	})
+	t.Run("describe", func(t *testing.T) {
+		specs, err := flagarize.Describe(&cmdConfig{})
+		testutil.Ok(t, err)
+		var cmds []string
+		for _, s := range specs {
+			cmds = append(cmds, s.Name+"@"+s.Command)
+		}
+		testutil.Equals(t, []string{"debug@", "concurrency@tools compact", "dry-run@tools compact", "selector@tools inspect"}, cmds)
+	})
+}
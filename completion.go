@@ -0,0 +1,374 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Shell is a shell the completion script is generated for by WriteCompletion.
+type Shell string
+
+const (
+	// ShellBash generates script for bash, that can be sourced or put in bash-completion directory.
+	ShellBash Shell = "bash"
+	// ShellZsh generates script for zsh, that can be sourced or put as _<name> file in directory from $fpath.
+	ShellZsh Shell = "zsh"
+	// ShellFish generates script for fish, that can be sourced or put in ~/.config/fish/completions/<name>.fish.
+	ShellFish Shell = "fish"
+)
+
+// WriteCompletion writes completion script of the given shell for the application with the given name, which flags are
+// flagarized from the given struct. Flags are registered the same way as by Describe, so the same options should be
+// passed. The script completes:
+//
+//   - flags (and --no- negations of bool flags) of the application and the current command;
+//   - commands, nested the same as nested structs with cmd=<name> struct tag;
+//   - allowed values of flags with `enum` struct tag;
+//   - file names for flags and positional arguments of *os.File fields, path flags of PathOrContent fields and
+//     config file and env file flags (see WithConfigFile and WithEnvFileFlag).
+//
+// Hidden flags and commands are not completed.
+func WriteCompletion(w io.Writer, shell Shell, name string, s interface{}, o ...OptFunc) error {
+	app := kingpin.New(name, "")
+	specs, err := describe(app, s, o)
+	if err != nil {
+		return err
+	}
+	c := &completion{appDoc: newDoc(app, specs), fn: "_" + nonIdentChars.ReplaceAllString(name, "_")}
+
+	var buf bytes.Buffer
+	switch shell {
+	case ShellBash:
+		c.bash(&buf)
+	case ShellZsh:
+		c.zsh(&buf)
+	case ShellFish:
+		c.fish(&buf)
+	default:
+		return errors.Errorf("flagarize: unsupported shell %q; only %q, %q and %q are supported", shell, ShellBash, ShellZsh, ShellFish)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+var nonIdentChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+type completion struct {
+	*appDoc
+	// fn is a prefix of shell functions.
+	fn string
+}
+
+// flags returns flags that can be used in the given section, so flags of the application and all parent commands.
+func (c *completion) flags(s docSection) []docEntry {
+	var flags []docEntry
+	for _, p := range c.sections {
+		if p.command == "" || p.command == s.command || strings.HasPrefix(s.command, p.command+" ") {
+			flags = append(flags, p.flags...)
+		}
+	}
+	return flags
+}
+
+// subcommands returns names of direct subcommands of the given section.
+func (c *completion) subcommands(s docSection) []string {
+	var names []string
+	for _, sub := range c.sections {
+		if sub.command != "" && parentCommand(sub.command) == s.command {
+			names = append(names, sub.command[strings.LastIndex(sub.command, " ")+1:])
+		}
+	}
+	return names
+}
+
+// commands returns full names of all commands.
+func (c *completion) commands() []string {
+	var names []string
+	for _, s := range c.sections[1:] {
+		names = append(names, s.command)
+	}
+	return names
+}
+
+// valueFlags returns names of all flags that take value, as passed in the command line.
+func (c *completion) valueFlags() []string {
+	var names []string
+	for _, s := range c.sections {
+		for _, f := range s.flags {
+			if !f.isBool {
+				names = append(names, f.options()...)
+			}
+		}
+	}
+	return names
+}
+
+func parentCommand(cmd string) string {
+	if i := strings.LastIndex(cmd, " "); i >= 0 {
+		return cmd[:i]
+	}
+	return ""
+}
+
+// fileTypes are Go types of fields (see FlagSpec.Type) which values are file names.
+var fileTypes = map[string]bool{"*os.File": true}
+
+// isFileValue returns true if value of the flag or argument with the given spec and kingpin value is a file name, so
+// file names are completed for it. It's based on types only: fields of fileTypes, path flag of PathOrContent and flags
+// registered by options (e.g --config.file).
+func isFileValue(spec FlagSpec, v kingpin.Value) bool {
+	switch v.(type) {
+	case *filePath, *configFile, *envFile:
+		return true
+	}
+	return fileTypes[spec.Type]
+}
+
+// completesFiles returns true if value of the flag or argument is a file name.
+func (e docEntry) completesFiles() bool { return e.files }
+
+// options returns flag names as passed in the command line e.g ["-m", "--mode"].
+func (e docEntry) options() []string {
+	if e.Short != 0 {
+		return []string{fmt.Sprintf("-%c", e.Short), "--" + e.Name}
+	}
+	return []string{"--" + e.Name}
+}
+
+// summary returns the first line of the help.
+func (e docEntry) summary() string {
+	return strings.SplitN(e.Help, "\n", 2)[0]
+}
+
+// fileArgs returns true if positional arguments of the section complete file names.
+func (s docSection) fileArgs() bool {
+	for _, a := range s.args {
+		if a.completesFiles() {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes string in single quotes for POSIX-like shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// commandDetection writes shell loop setting cmd variable to the command typed in words of the given array (bash and zsh
+// syntax). Values of flags are skipped, so they are not taken as commands.
+func (c *completion) commandDetection(w io.Writer, words, from, to string) {
+	cmds := c.commands()
+	if len(cmds) == 0 {
+		return
+	}
+	quoted := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		quoted = append(quoted, shellQuote(cmd))
+	}
+	valueFlags := c.valueFlags()
+	fmt.Fprintf(w, "    for ((i = %s; i < %s; i++)); do\n", from, to)
+	fmt.Fprintf(w, "        w=\"${%s[i]}\"\n", words)
+	fmt.Fprint(w, "        case \"$w\" in\n")
+	if len(valueFlags) > 0 {
+		fmt.Fprintf(w, "        %s)\n", strings.Join(valueFlags, "|"))
+		fmt.Fprintf(w, "            [[ \"${%s[i+1]}\" == \"=\" ]] && ((i++))\n", words)
+		fmt.Fprint(w, "            ((i++))\n            ;;\n")
+	}
+	fmt.Fprint(w, "        *)\n")
+	fmt.Fprint(w, "            case \"${cmd:+$cmd }$w\" in\n")
+	fmt.Fprintf(w, "            %s) cmd=\"${cmd:+$cmd }$w\" ;;\n", strings.Join(quoted, "|"))
+	fmt.Fprint(w, "            esac\n            ;;\n        esac\n    done\n")
+}
+
+func (c *completion) bash(w io.Writer) {
+	fmt.Fprintf(w, "# bash completion for %s generated by flagarize.\n\n", c.name)
+	fmt.Fprintf(w, "%s() {\n", c.fn)
+	fmt.Fprint(w, "    local cur prev cmd w i\n")
+	fmt.Fprint(w, "    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprint(w, "    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprint(w, "    # Support --flag=value, as = is a word break.\n")
+	fmt.Fprint(w, "    if [[ \"$cur\" == \"=\" ]]; then\n        cur=\"\"\n")
+	fmt.Fprint(w, "    elif [[ \"$prev\" == \"=\" ]]; then\n        prev=\"${COMP_WORDS[COMP_CWORD-2]}\"\n    fi\n")
+	fmt.Fprint(w, "    cmd=\"\"\n")
+	c.commandDetection(w, "COMP_WORDS", "1", "COMP_CWORD")
+	fmt.Fprint(w, "    case \"$cmd\" in\n")
+	for _, s := range c.sections {
+		fmt.Fprintf(w, "    %s)\n", shellQuote(s.command))
+		flags := c.flags(s)
+
+		var values, noValues []string
+		for _, f := range flags {
+			if f.isBool {
+				continue
+			}
+			pattern := strings.Join(f.options(), "|")
+			switch {
+			case len(f.Constraints.Enum) > 0:
+				values = append(values, fmt.Sprintf("        %s)\n            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n            return\n            ;;\n", pattern, shellQuote(strings.Join(f.Constraints.Enum, " "))))
+			case f.completesFiles():
+				values = append(values, fmt.Sprintf("        %s)\n            compopt -o filenames 2>/dev/null\n            COMPREPLY=($(compgen -f -- \"$cur\"))\n            return\n            ;;\n", pattern))
+			default:
+				noValues = append(noValues, pattern)
+			}
+		}
+		if len(noValues) > 0 {
+			// Values of other flags are not completed.
+			values = append(values, fmt.Sprintf("        %s)\n            return\n            ;;\n", strings.Join(noValues, "|")))
+		}
+		if len(values) > 0 {
+			fmt.Fprintf(w, "        case \"$prev\" in\n%s        esac\n", strings.Join(values, ""))
+		}
+
+		var words []string
+		for _, f := range flags {
+			words = append(words, f.options()...)
+			if f.isBool {
+				words = append(words, "--no-"+f.Name)
+			}
+		}
+		words = append(words, "--help")
+		fmt.Fprint(w, "        if [[ \"$cur\" == -* ]]; then\n")
+		fmt.Fprintf(w, "            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(words, " ")))
+		switch subs := c.subcommands(s); {
+		case len(subs) > 0:
+			fmt.Fprintf(w, "        else\n            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(subs, " ")))
+		case s.fileArgs():
+			fmt.Fprint(w, "        else\n            compopt -o filenames 2>/dev/null\n            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		}
+		fmt.Fprint(w, "        fi\n        ;;\n")
+	}
+	fmt.Fprint(w, "    esac\n}\n\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", c.fn, shellQuote(c.name))
+}
+
+// zshDescription escapes description for zsh _arguments spec.
+func zshDescription(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+func (c *completion) zsh(w io.Writer) {
+	fmt.Fprintf(w, "#compdef %s\n\n# zsh completion for %s generated by flagarize.\n\n", c.name, c.name)
+	fmt.Fprintf(w, "%s() {\n", c.fn)
+	fmt.Fprint(w, "    local cmd w i\n    cmd=\"\"\n")
+	c.commandDetection(w, "words", "2", "CURRENT")
+	fmt.Fprint(w, "    case \"$cmd\" in\n")
+	for _, s := range c.sections {
+		fmt.Fprintf(w, "    %s)\n", shellQuote(s.command))
+		fmt.Fprint(w, "        _arguments -s \\\n")
+		for _, f := range c.flags(s) {
+			desc := zshDescription(f.summary())
+			if f.isBool {
+				exclusion := fmt.Sprintf("(%s --no-%s)", strings.Join(f.options(), " "), f.Name)
+				for _, o := range f.options() {
+					fmt.Fprintf(w, "            %s \\\n", shellQuote(fmt.Sprintf("%s%s[%s]", exclusion, o, desc)))
+				}
+				fmt.Fprintf(w, "            %s \\\n", shellQuote(fmt.Sprintf("%s--no-%s[%s]", exclusion, f.Name, desc)))
+				continue
+			}
+
+			action := " "
+			switch {
+			case len(f.Constraints.Enum) > 0:
+				action = "(" + strings.Join(f.Constraints.Enum, " ") + ")"
+			case f.completesFiles():
+				action = "_files"
+			}
+			exclusion := fmt.Sprintf("(%s)", strings.Join(f.options(), " "))
+			if f.repeatable {
+				exclusion = "*"
+			}
+			for _, o := range f.options() {
+				if strings.HasPrefix(o, "--") {
+					o += "="
+				}
+				fmt.Fprintf(w, "            %s \\\n", shellQuote(fmt.Sprintf("%s%s[%s]:%s:%s", exclusion, o, desc, f.Name, action)))
+			}
+		}
+		switch subs := c.subcommands(s); {
+		case len(subs) > 0:
+			fmt.Fprintf(w, "            %s \\\n", shellQuote(fmt.Sprintf("*: :(%s)", strings.Join(subs, " "))))
+		case s.fileArgs():
+			fmt.Fprint(w, "            '*: :_files' \\\n")
+		}
+		fmt.Fprint(w, "            '--help[Show context-sensitive help.]'\n        ;;\n")
+	}
+	fmt.Fprint(w, "    esac\n}\n\n")
+	fmt.Fprintf(w, "if [[ \"$funcstack[1]\" == \"%s\" ]]; then\n    %s \"$@\"\nelse\n    compdef %s %s\nfi\n", c.fn, c.fn, c.fn, shellQuote(c.name))
+}
+
+// fishQuote quotes string in single quotes for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func (c *completion) fish(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for %s generated by flagarize.\n\n", c.name)
+	name := fishQuote(c.name)
+	cmds := c.commands()
+	if len(cmds) > 0 {
+		quoted := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			quoted = append(quoted, fishQuote(cmd))
+		}
+		valueFlags := c.valueFlags()
+		fmt.Fprintf(w, "function %s_cmd\n    set -l cmd\n    set -l skip 0\n    for t in (commandline -opc)[2..-1]\n", c.fn)
+		fmt.Fprint(w, "        if test $skip = 1\n            set skip 0\n            continue\n        end\n")
+		if len(valueFlags) > 0 {
+			fmt.Fprintf(w, "        switch $t\n            case %s\n                set skip 1\n                continue\n        end\n", strings.Join(valueFlags, " "))
+		}
+		fmt.Fprintf(w, "        switch (string join ' ' $cmd $t)\n            case %s\n                set cmd $cmd $t\n        end\n    end\n", strings.Join(quoted, " "))
+		fmt.Fprint(w, "    string join ' ' $cmd\nend\n\n")
+		fmt.Fprintf(w, "function %s_at_cmd\n    set -l c (%s_cmd)\n    test \"$c\" = \"$argv[1]\"\nend\n\n", c.fn, c.fn)
+		fmt.Fprintf(w, "function %s_in_cmd\n    set -l c (%s_cmd)\n    test \"$c\" = \"$argv[1]\"; or string match -q -- \"$argv[1] *\" \"$c\"\nend\n\n", c.fn, c.fn)
+	}
+
+	fmt.Fprintf(w, "complete -c %s -f\n", name)
+	fmt.Fprintf(w, "complete -c %s -l help -d 'Show context-sensitive help.'\n", name)
+	for _, s := range c.sections {
+		cond := ""
+		if s.command != "" {
+			cond = fmt.Sprintf(" -n '%s_in_cmd \"%s\"'", c.fn, s.command)
+		}
+		for _, f := range s.flags {
+			desc := fishQuote(f.summary())
+			opt := "-l " + f.Name
+			if f.Short != 0 {
+				opt += fmt.Sprintf(" -s %c", f.Short)
+			}
+			switch {
+			case f.isBool:
+				fmt.Fprintf(w, "complete -c %s%s %s -d %s\n", name, cond, opt, desc)
+				fmt.Fprintf(w, "complete -c %s%s -l no-%s -d %s\n", name, cond, f.Name, desc)
+			case len(f.Constraints.Enum) > 0:
+				fmt.Fprintf(w, "complete -c %s%s %s -x -a %s -d %s\n", name, cond, opt, fishQuote(strings.Join(f.Constraints.Enum, " ")), desc)
+			case f.completesFiles():
+				fmt.Fprintf(w, "complete -c %s%s %s -r -F -d %s\n", name, cond, opt, desc)
+			default:
+				fmt.Fprintf(w, "complete -c %s%s %s -x -d %s\n", name, cond, opt, desc)
+			}
+		}
+
+		at := ""
+		if len(cmds) > 0 {
+			at = fmt.Sprintf(" -n '%s_at_cmd \"%s\"'", c.fn, s.command)
+		}
+		for _, sub := range c.sections {
+			if sub.command != "" && parentCommand(sub.command) == s.command {
+				fmt.Fprintf(w, "complete -c %s%s -a %s -d %s\n", name, at, sub.command[strings.LastIndex(sub.command, " ")+1:], fishQuote(strings.SplitN(sub.help, "\n", 2)[0]))
+			}
+		}
+		if len(c.subcommands(s)) == 0 && s.fileArgs() {
+			fmt.Fprintf(w, "complete -c %s%s -F\n", name, at)
+		}
+	}
+}
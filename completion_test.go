@@ -0,0 +1,96 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type completionCompactConfig struct {
+	DryRun bool     `flagarize:"name=dry-run|help=Dry run."`
+	Input  *os.File `flagarize:"arg=input|help=File to compact."`
+}
+
+type completionToolsConfig struct {
+	Compact completionCompactConfig `flagarize:"cmd=compact|help=Compact blocks."`
+	Hidden  struct{}                `flagarize:"cmd=hidden|help=Hidden.|hidden=true"`
+}
+
+type completionConfig struct {
+	Mode    string                  `flagarize:"name=mode|help=Mode.|enum=fast|enum=slow|short=m"`
+	Config  flagarize.PathOrContent `flagarize:"name=config|help=config."`
+	In      *os.File                `flagarize:"name=in|help=Input."`
+	Profile string                  `flagarize:"name=profile|help=Profile.|placeholder=<profile>"`
+	Debug   bool                    `flagarize:"name=debug|help=Debug."`
+	Tools   completionToolsConfig   `flagarize:"cmd=tools|help=Tools."`
+}
+
+func TestWriteCompletion(t *testing.T) {
+	for _, tcase := range []struct {
+		shell    flagarize.Shell
+		expected []string
+	}{
+		{
+			shell: flagarize.ShellBash,
+			expected: []string{
+				"'tools'|'tools compact') cmd=\"${cmd:+$cmd }$w\" ;;",
+				"        -m|--mode)\n            COMPREPLY=($(compgen -W 'fast slow' -- \"$cur\"))\n",
+				"        --config-file)\n            compopt -o filenames 2>/dev/null\n            COMPREPLY=($(compgen -f -- \"$cur\"))\n",
+				"        --in)\n            compopt -o filenames 2>/dev/null\n            COMPREPLY=($(compgen -f -- \"$cur\"))\n",
+				"        --config|--profile)\n            return\n",
+				"COMPREPLY=($(compgen -W '-m --mode --config-file --config --in --profile --debug --no-debug --help' -- \"$cur\"))",
+				"    'tools')\n",
+				"COMPREPLY=($(compgen -W 'compact' -- \"$cur\"))",
+				"--debug --no-debug --dry-run --no-dry-run --help",
+				"complete -F _app 'app'\n",
+			},
+		},
+		{
+			shell: flagarize.ShellZsh,
+			expected: []string{
+				"#compdef app\n",
+				"'(-m --mode)--mode=[Mode. (one of: fast, slow)]:mode:(fast slow)'",
+				"'(--config-file)--config-file=[Path to config.]:config-file:_files'",
+				"'(--in)--in=[Input.]:in:_files'",
+				"'(--profile)--profile=[Profile.]:profile: '",
+				"'(--debug --no-debug)--no-debug[Debug.]'",
+				"'*: :(compact)'",
+				"'*: :_files'",
+				"compdef _app 'app'",
+			},
+		},
+		{
+			shell: flagarize.ShellFish,
+			expected: []string{
+				"case 'tools' 'tools compact'\n",
+				"complete -c 'app' -l mode -s m -x -a 'fast slow' -d 'Mode. (one of: fast, slow)'\n",
+				"complete -c 'app' -l config-file -r -F -d 'Path to config.'\n",
+				"complete -c 'app' -l in -r -F -d 'Input.'\n",
+				"complete -c 'app' -l profile -x -d 'Profile.'\n",
+				"complete -c 'app' -l no-debug -d 'Debug.'\n",
+				"complete -c 'app' -n '_app_at_cmd \"tools\"' -a compact -d 'Compact blocks.'\n",
+				"complete -c 'app' -n '_app_in_cmd \"tools compact\"' -l no-dry-run -d 'Dry run.'\n",
+				"complete -c 'app' -n '_app_at_cmd \"tools compact\"' -F\n",
+			},
+		},
+	} {
+		t.Run(string(tcase.shell), func(t *testing.T) {
+			var b bytes.Buffer
+			testutil.Ok(t, flagarize.WriteCompletion(&b, tcase.shell, "app", &completionConfig{}))
+			for _, exp := range tcase.expected {
+				testutil.Assert(t, strings.Contains(b.String(), exp), "expected %q in:\n%s", exp, b.String())
+			}
+			testutil.Assert(t, !strings.Contains(b.String(), "hidden"), "hidden command completed:\n%s", b.String())
+		})
+	}
+	t.Run("unsupported shell", func(t *testing.T) {
+		testutil.NotOk(t, flagarize.WriteCompletion(&bytes.Buffer{}, "powershell", "app", &completionConfig{}))
+	})
+}
@@ -0,0 +1,352 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	configFileFlagName = "config.file"
+	keyStructTagKey    = "key"
+)
+
+// WithConfigFile registers --config.file=<path> flag. If specified, values of flags are loaded from the given JSON file
+// with the following precedence: flag, environment variable, config file, default.
+//
+// Keys of the JSON object are flag names or keys given in `key` struct tag. Nested objects are joined with dot, so
+// `{"web": {"timeout": "5m"}}` is the same as `{"web.timeout": "5m"}`. Values are strings, numbers or bools, arrays for
+// repeatable flags and objects for maps. Unknown keys are reported with their location in the file.
+// It requires KingpinRegistry to be *kingpin.Application or *kingpin.CmdClause.
+func WithConfigFile() OptFunc { return func(opt *opts) { opt.withConfigFile = true } }
+
+// configFile loads values of flags from the JSON config file. It is also kingpin.Value of the --config.file flag.
+type configFile struct {
+	path string
+	keys map[string]*fileField
+	// elements are parse elements added for values from the config file on the last parse.
+	elements sourceElements
+}
+
+// fileField is a flag which value can be loaded from the config file, env file or custom source.
+type fileField struct {
+	flag *kingpin.FlagClause
+	// value is the field value if the flag is the only flag registered for the field, invalid otherwise.
+	value reflect.Value
+}
+
+// setValues sets values of the flag. For repeatable fields, values set before (e.g defaults kingpin appended already)
+// are dropped first.
+func (ff *fileField) setValues(values []string) error {
+	if ff.value.IsValid() && isCumulative(ff.flag.Model().Value) {
+		ff.value.Set(reflect.Zero(ff.value.Type()))
+		if ff.value.Kind() == reflect.Map {
+			ff.value.Set(reflect.MakeMap(ff.value.Type()))
+		}
+	}
+	for _, v := range values {
+		if err := ff.flag.Model().Value.Set(v); err != nil {
+			return errors.Wrapf(err, "invalid value %q", v)
+		}
+	}
+	return nil
+}
+
+// installConfigFile registers --config.file flag in the registry, unless it was already registered.
+func installConfigFile(r KingpinRegistry) (*configFile, error) {
+	if f := r.GetFlag(configFileFlagName); f != nil {
+		c, ok := f.Model().Value.(*configFile)
+		if !ok {
+			return nil, errors.Errorf("config file requires --%s flag, but it was already registered", configFileFlagName)
+		}
+		return c, nil
+	}
+
+	c := &configFile{keys: map[string]*fileField{}, elements: sourceElements{}}
+	if err := addPreAction(r, c.apply); err != nil {
+		return nil, errors.Wrap(err, "config file")
+	}
+	r.Flag(configFileFlagName, "Path to JSON config file with values of flags. Flags and environment variables have priority over it.").
+		PlaceHolder("<path>").SetValue(c)
+	return c, nil
+}
+
+// Set implements kingpin.Value.
+func (c *configFile) Set(path string) error {
+	c.path = path
+	return nil
+}
+
+// String implements kingpin.Value.
+func (c *configFile) String() string { return c.path }
+
+// add adds flags registered for the field, so they can be loaded from the config file. It's noop for nil configFile.
+func (c *configFile) add(tag *Tag, fieldValue reflect.Value, flags ...*kingpin.FlagClause) error {
+	if c == nil {
+		return nil
+	}
+	if tag.Key != "" && len(flags) != 1 {
+		return errors.Errorf("%s struct tag key is supported only for fields registering single flag, got %d flags", keyStructTagKey, len(flags))
+	}
+	for _, f := range flags {
+		key := f.Model().Name
+		if tag.Key != "" {
+			key = tag.Key
+		}
+		if _, ok := c.keys[key]; ok {
+			return errors.Errorf("config file key %q is already used", key)
+		}
+		ff := &fileField{flag: f}
+		if len(flags) == 1 {
+			ff.value = fieldValue
+		}
+		c.keys[key] = ff
+	}
+	return nil
+}
+
+// apply sets values from the config file for flags that were not set by flag nor environment variable. It runs
+// before required flags are checked, so config file can provide values of required flags.
+func (c *configFile) apply(ctx *kingpin.ParseContext) error {
+	c.elements.reset()
+
+	if c.path == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return errors.Wrap(err, "read config file")
+	}
+	entries, err := parseConfigFile(b, c.path, func(key string) (known, isMap bool) {
+		ff, ok := c.keys[key]
+		return ok, ok && ff.value.IsValid() && ff.value.Kind() == reflect.Map
+	})
+	if err != nil {
+		return err
+	}
+
+	var merr MultiError
+	for _, e := range entries {
+		ff, ok := c.keys[e.key]
+		if !ok {
+			msg := fmt.Sprintf("%s: unknown key %q", e.location, e.key)
+			if s := suggestKey(e.key, c.knownKeys()); s != "" {
+				msg += fmt.Sprintf("; did you mean %q?", s)
+			}
+			merr.Append(errors.New(msg))
+			continue
+		}
+		if e.values == nil {
+			continue
+		}
+
+		if !isFlagSet(ctx, ff.flag) {
+			if err := ff.setValues(e.values); err != nil {
+				merr.Append(errors.Wrapf(err, "%s: key %q", e.location, e.key))
+			}
+		}
+		for _, v := range e.values {
+			c.elements.add(ctx, ff.flag, v, elementSource{source: SourceConfigFile, location: e.location})
+		}
+	}
+	return merr.Err()
+}
+
+func (c *configFile) knownKeys() []string {
+	keys := make([]string, 0, len(c.keys))
+	for k := range c.keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fileEntry is a value of the key from the config file.
+type fileEntry struct {
+	key string
+	// values are values of the flag. Nil for JSON null.
+	values   []string
+	location string
+}
+
+// parseConfigFile returns entries of the JSON config file in the order of the file. Nested objects are flattened
+// into dotted keys, unless they are values of known map keys.
+func parseConfigFile(b []byte, path string, lookup func(key string) (known, isMap bool)) ([]fileEntry, error) {
+	p := &fileParser{dec: json.NewDecoder(bytes.NewReader(b)), b: b, path: path, lookup: lookup}
+	p.dec.UseNumber()
+
+	t, err := p.dec.Token()
+	if err != nil {
+		return nil, p.errorf("parse config file: %v", err)
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, p.errorf("parse config file: expected JSON object")
+	}
+	if err := p.object(""); err != nil {
+		return nil, err
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		return nil, p.errorf("parse config file: unexpected data after JSON object")
+	}
+	return p.entries, nil
+}
+
+type fileParser struct {
+	dec     *json.Decoder
+	b       []byte
+	path    string
+	lookup  func(key string) (known, isMap bool)
+	entries []fileEntry
+}
+
+// location returns path:line:column of the given offset in the file.
+func (p *fileParser) location(offset int64) string {
+	before := p.b[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("%s:%d:%d", p.path, line, col)
+}
+
+func (p *fileParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("%s: %s", p.location(p.dec.InputOffset()), fmt.Sprintf(format, args...))
+}
+
+// keyOffset returns offset of the key that was just read.
+func (p *fileParser) keyOffset() int64 {
+	end := p.dec.InputOffset() - 1
+	for i := end - 1; i >= 0; i-- {
+		if p.b[i] == '"' && (i == 0 || p.b[i-1] != '\\') {
+			return i
+		}
+	}
+	return end
+}
+
+// object parses object which opening delimiter was already read.
+func (p *fileParser) object(prefix string) error {
+	for p.dec.More() {
+		t, err := p.dec.Token()
+		if err != nil {
+			return p.errorf("parse config file: %v", err)
+		}
+		key := prefix + t.(string)
+		loc := p.location(p.keyOffset())
+
+		known, isMap := p.lookup(key)
+		t, err = p.dec.Token()
+		if err != nil {
+			return p.errorf("parse config file: %v", err)
+		}
+		d, isDelim := t.(json.Delim)
+		switch {
+		case isDelim && d == '{' && !known:
+			if err := p.object(key + "."); err != nil {
+				return err
+			}
+			continue
+		case !known:
+			if isDelim {
+				if err := p.skip(); err != nil {
+					return err
+				}
+			}
+			p.entries = append(p.entries, fileEntry{key: key, location: loc})
+			continue
+		}
+
+		e := fileEntry{key: key, location: loc}
+		switch {
+		case isDelim && d == '{' && isMap:
+			e.values = []string{}
+			for p.dec.More() {
+				k, err := p.dec.Token()
+				if err != nil {
+					return p.errorf("parse config file: %v", err)
+				}
+				v, err := p.scalar(key)
+				if err != nil {
+					return err
+				}
+				e.values = append(e.values, fmt.Sprintf("%s=%s", k, v))
+			}
+			if _, err := p.dec.Token(); err != nil {
+				return p.errorf("parse config file: %v", err)
+			}
+		case isDelim && d == '[':
+			e.values = []string{}
+			for p.dec.More() {
+				v, err := p.scalar(key)
+				if err != nil {
+					return err
+				}
+				e.values = append(e.values, v)
+			}
+			if _, err := p.dec.Token(); err != nil {
+				return p.errorf("parse config file: %v", err)
+			}
+		case isDelim:
+			return errors.Errorf("%s: object is supported only for map flags, got object for key %q", loc, key)
+		case t != nil:
+			e.values = []string{scalarString(t)}
+		}
+		p.entries = append(p.entries, e)
+	}
+	_, err := p.dec.Token()
+	if err != nil {
+		return p.errorf("parse config file: %v", err)
+	}
+	return nil
+}
+
+// scalar reads scalar value of array or map element.
+func (p *fileParser) scalar(key string) (string, error) {
+	t, err := p.dec.Token()
+	if err != nil {
+		return "", p.errorf("parse config file: %v", err)
+	}
+	if _, ok := t.(json.Delim); ok || t == nil {
+		return "", p.errorf("only strings, numbers and bools are supported as elements of key %q", key)
+	}
+	return scalarString(t), nil
+}
+
+// skip skips the rest of the object or array which opening delimiter was already read.
+func (p *fileParser) skip() error {
+	for depth := 1; depth > 0; {
+		t, err := p.dec.Token()
+		if err != nil {
+			return p.errorf("parse config file: %v", err)
+		}
+		if d, ok := t.(json.Delim); ok {
+			if d == '{' || d == '[' {
+				depth++
+				continue
+			}
+			depth--
+		}
+	}
+	return nil
+}
+
+func scalarString(t json.Token) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(t)
+}
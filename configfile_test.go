@@ -0,0 +1,116 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type fileWebConfig struct {
+	Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|envvar=FILE_WEB_TIMEOUT"`
+	Address string        `flagarize:"name=address|help=Address.|default=:80"`
+}
+
+type fileConfig struct {
+	Web     fileWebConfig     `flagarize:"prefix=web."`
+	Name    string            `flagarize:"name=name|help=Name.|required=true"`
+	Debug   bool              `flagarize:"name=debug|help=Debug."`
+	Peers   []string          `flagarize:"name=peer|help=Peers.|default=a"`
+	Labels  map[string]string `flagarize:"name=label|help=Labels."`
+	Retries int               `flagarize:"name=retries|help=Retries.|key=max_retries|max=10"`
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "flagarize")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(dir)) })
+
+	p := filepath.Join(dir, "config.json")
+	testutil.Ok(t, ioutil.WriteFile(p, []byte(content), os.ModePerm))
+	return p
+}
+
+func TestFlagarize_ConfigFile(t *testing.T) {
+	t.Run("precedence", func(t *testing.T) {
+		testutil.Ok(t, os.Setenv("FILE_WEB_TIMEOUT", "5m"))
+		defer func() { testutil.Ok(t, os.Unsetenv("FILE_WEB_TIMEOUT")) }()
+
+		p := writeConfigFile(t, `{
+  "web": {"timeout": "10m", "address": ":8080"},
+  "name": "from-file",
+  "debug": true,
+  "peer": ["b", "c"],
+  "label": {"a": "1", "b": 2},
+  "max_retries": 3
+}`)
+		app := newTestKingpin(t)
+		cfg := &fileConfig{}
+		f, err := flagarize.New(app, cfg, flagarize.WithConfigFile())
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"--config.file=" + p, "--name=from-flag"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, &fileConfig{
+			Web:     fileWebConfig{Timeout: 5 * time.Minute, Address: ":8080"},
+			Name:    "from-flag",
+			Debug:   true,
+			Peers:   []string{"b", "c"},
+			Labels:  map[string]string{"a": "1", "b": "2"},
+			Retries: 3,
+		}, cfg)
+
+		sources, err := f.Sources()
+		testutil.Ok(t, err)
+		testutil.Equals(t, flagarize.FieldSource{
+			Path: flagarize.FieldPath{"Web", "Timeout"}, Flag: "web.timeout",
+			SourceValue: flagarize.SourceValue{Source: flagarize.SourceEnv, Raw: []string{"5m"}},
+			Overridden: []flagarize.SourceValue{
+				{Source: flagarize.SourceConfigFile, Raw: []string{"10m"}},
+				{Source: flagarize.SourceDefault, Raw: []string{"1m"}},
+			},
+		}, sources["Web.Timeout"])
+		testutil.Equals(t, flagarize.SourceFlag, sources["Name"].Source)
+		testutil.Equals(t, flagarize.SourceConfigFile, sources["Peers"].Source)
+	})
+	t.Run("required and constraints", func(t *testing.T) {
+		p := writeConfigFile(t, `{"max_retries": 30}`)
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &fileConfig{}, flagarize.WithConfigFile()))
+
+		_, err := app.Parse([]string{"--config.file=" + p})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "required flag --name not provided", err.Error())
+
+		p = writeConfigFile(t, `{"max_retries": 30, "name": "a"}`)
+		_, err = app.Parse([]string{"--config.file=" + p})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "invalid value \"30\" from config file "+p+":1:2 (flag --retries): has to be less than or equal to 10", err.Error())
+	})
+	t.Run("unknown keys", func(t *testing.T) {
+		p := writeConfigFile(t, `{
+  "name": "a",
+  "web": {
+    "timeotu": "1m"
+  },
+  "storage": {"bucket": "x"},
+  "retries": 1
+}`)
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &fileConfig{}, flagarize.WithConfigFile()))
+
+		_, err := app.Parse([]string{"--config.file=" + p})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "3 error(s) occurred:\n"+
+			"* "+p+":4:5: unknown key \"web.timeotu\"; did you mean \"web.timeout\"?\n"+
+			"* "+p+":6:15: unknown key \"storage.bucket\"\n"+
+			"* "+p+":7:3: unknown key \"retries\"", err.Error())
+	})
+}
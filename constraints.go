@@ -0,0 +1,192 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	requiresStructTagKey  = "requires"
+	conflictsStructTagKey = "conflicts"
+	oneofStructTagKey     = "oneof"
+)
+
+// constraints are cross-flag constraints of the flags registered in a single Flagarize invocation. They are checked
+// after parse and all violations are aggregated into one error.
+type constraints struct {
+	requiredTogether []*requiredTogether
+	relations        []*flagRelations
+
+	oneOfOrder []string
+	oneOf      map[string][]*kingpin.FlagClause
+
+	values []*valueCheck
+}
+
+// flagRelations are flags that are required or conflicting with the given flag when it's set.
+type flagRelations struct {
+	flag *kingpin.FlagClause
+
+	requires  []string
+	conflicts []string
+
+	requiresFlags  []*kingpin.FlagClause
+	conflictsFlags []*kingpin.FlagClause
+}
+
+func newConstraints() *constraints {
+	return &constraints{oneOf: map[string][]*kingpin.FlagClause{}}
+}
+
+// add adds constraints specified in the tag for the given registered flags.
+func (c *constraints) add(tag *Tag, flags ...*kingpin.FlagClause) error {
+	if len(tag.Requires) == 0 && len(tag.Conflicts) == 0 && tag.OneOf == "" {
+		return nil
+	}
+	if len(flags) != 1 {
+		return errors.Errorf("%s, %s and %s are supported only for fields registering single flag, got %d for flag %s",
+			requiresStructTagKey, conflictsStructTagKey, oneofStructTagKey, len(flags), tag.Name)
+	}
+
+	if len(tag.Requires) > 0 || len(tag.Conflicts) > 0 {
+		c.relations = append(c.relations, &flagRelations{flag: flags[0], requires: tag.Requires, conflicts: tag.Conflicts})
+	}
+	if tag.OneOf != "" {
+		if _, ok := c.oneOf[tag.OneOf]; !ok {
+			c.oneOfOrder = append(c.oneOfOrder, tag.OneOf)
+		}
+		c.oneOf[tag.OneOf] = append(c.oneOf[tag.OneOf], flags[0])
+	}
+	return nil
+}
+
+// install resolves flags referenced by constraints and registers check of all constraints as post-parse action
+// if there are any constraints. Referenced flags have to be registered in the same registry or in one of parents
+// (application and commands the registry is nested in). Flags conflicting with each other are checked once.
+func (c *constraints) install(r KingpinRegistry, parents []KingpinRegistry) error {
+	if len(c.requiredTogether) == 0 && len(c.relations) == 0 && len(c.oneOf) == 0 && len(c.values) == 0 {
+		return nil
+	}
+	getFlag := func(name string) *kingpin.FlagClause {
+		if f := r.GetFlag(name); f != nil {
+			return f
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			if f := parents[i].GetFlag(name); f != nil {
+				return f
+			}
+		}
+		return nil
+	}
+	conflicting := map[[2]*kingpin.FlagClause]bool{}
+	for _, rel := range c.relations {
+		for _, n := range rel.requires {
+			f := getFlag(n)
+			if f == nil {
+				return errors.Errorf("flag --%s requires flag --%s that is not registered", rel.flag.Model().Name, n)
+			}
+			rel.requiresFlags = append(rel.requiresFlags, f)
+		}
+		for _, n := range rel.conflicts {
+			f := getFlag(n)
+			if f == nil {
+				return errors.Errorf("flag --%s conflicts with flag --%s that is not registered", rel.flag.Model().Name, n)
+			}
+			if conflicting[[2]*kingpin.FlagClause{f, rel.flag}] {
+				continue
+			}
+			conflicting[[2]*kingpin.FlagClause{rel.flag, f}] = true
+			rel.conflictsFlags = append(rel.conflictsFlags, f)
+		}
+	}
+	return addPostParseAction(r, c.check)
+}
+
+func (c *constraints) check(ctx *kingpin.ParseContext) error {
+	var merr MultiError
+	for _, r := range c.requiredTogether {
+		merr.Append(r.check(ctx))
+	}
+	for _, r := range c.relations {
+		if !isFlagSet(ctx, r.flag) {
+			continue
+		}
+		for _, f := range r.requiresFlags {
+			if !isFlagSet(ctx, f) {
+				merr.Append(errors.Errorf("flag --%s requires flag --%s to be set", r.flag.Model().Name, f.Model().Name))
+			}
+		}
+		for _, f := range r.conflictsFlags {
+			if isFlagSet(ctx, f) {
+				merr.Append(errors.Errorf("flag --%s conflicts with flag --%s; only one can be set", r.flag.Model().Name, f.Model().Name))
+			}
+		}
+	}
+	for _, g := range c.oneOfOrder {
+		var set, all []string
+		for _, f := range c.oneOf[g] {
+			all = append(all, "--"+f.Model().Name)
+			if isFlagSet(ctx, f) {
+				set = append(set, "--"+f.Model().Name)
+			}
+		}
+		if len(set) > 1 {
+			merr.Append(errors.Errorf("only one of flags %s (%s) can be set, got %s", strings.Join(all, ", "), g, strings.Join(set, ", ")))
+		}
+	}
+	for _, v := range c.values {
+		merr.Append(v.check(ctx))
+	}
+	return merr.Err()
+}
+
+// isFlagSet returns true if flag was explicitly set in command line or via environment variable.
+func isFlagSet(ctx *kingpin.ParseContext, f *kingpin.FlagClause) bool {
+	for _, e := range ctx.Elements {
+		if e.Clause == f {
+			return true
+		}
+	}
+	return f.HasEnvarValue()
+}
+
+// addPreAction registers action invoked before required flags are checked and actions of flags are invoked, but
+// after values from command line and environment variables are set.
+func addPreAction(r KingpinRegistry, action kingpin.Action) error {
+	switch a := r.(type) {
+	case interface {
+		PreAction(kingpin.Action) *kingpin.Application
+	}:
+		a.PreAction(action)
+	case interface {
+		PreAction(kingpin.Action) *kingpin.CmdClause
+	}:
+		a.PreAction(action)
+	default:
+		return errors.Errorf("registry %T does not allow registering actions", r)
+	}
+	return nil
+}
+
+// addPostParseAction registers action invoked after successful parse, so when all values are set and
+// required flags are checked.
+func addPostParseAction(r KingpinRegistry, action kingpin.Action) error {
+	switch a := r.(type) {
+	case interface {
+		Action(kingpin.Action) *kingpin.Application
+	}:
+		a.Action(action)
+	case interface {
+		Action(kingpin.Action) *kingpin.CmdClause
+	}:
+		a.Action(action)
+	default:
+		return errors.Errorf("registry %T does not allow registering actions, which are required to check flags after parse", r)
+	}
+	return nil
+}
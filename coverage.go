@@ -0,0 +1,131 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UncoveredFieldError is reported for exported field that is silently not flagarized.
+type UncoveredFieldError struct {
+	FieldPos
+	// ShadowedBy is the path of the field registered by custom Flagarizer, if this field has flagarize struct tag that
+	// is ignored because of it. It is empty for fields without flagarize struct tag.
+	ShadowedBy FieldPath
+}
+
+func (e *UncoveredFieldError) Error() string {
+	if len(e.ShadowedBy) > 0 {
+		return fmt.Sprintf("%s: flagarize struct Tag is ignored, because %s is registered by custom Flagarizer", e.FieldPos, e.ShadowedBy)
+	}
+	return fmt.Sprintf("%s: exported field is not flagarized; add flagarize struct Tag or `flagarize:\"-\"` to ignore it", e.FieldPos)
+}
+
+// CoverageReport reports which fields of the struct are flagarized and which are not.
+type CoverageReport struct {
+	// Flagarized are positions of fields with flagarize struct tag that register flags.
+	Flagarized []FieldPos
+	// Uncovered are exported fields that are not flagarized, nor ignored with `flagarize:"-"`.
+	Uncovered []*UncoveredFieldError
+}
+
+// Err returns MultiError with all uncovered fields or nil if there are none.
+func (r CoverageReport) Err() error {
+	var merr MultiError
+	for _, u := range r.Uncovered {
+		merr.Append(u)
+	}
+	return merr.Err()
+}
+
+// Coverage returns report of flagarized and silently not flagarized fields of the given pointer to struct, without
+// registering anything. Not flagarized are exported leaf fields without flagarize struct tag (nested structs without
+// tag are inspected recursively) and tagged fields inside types registered by custom Flagarizer. Fields with
+// `flagarize:"-"` struct tag are ignored. WithStrict option makes Flagarize fail on uncovered fields.
+func Coverage(s interface{}, o ...OptFunc) (CoverageReport, error) {
+	v, err := structValue(s)
+	if err != nil {
+		return CoverageReport{}, err
+	}
+	opt := opts{elemSep: "|"}.apply(o...)
+	opt.root = v.Type().Name()
+	if opt.root == "" {
+		opt.root = v.Type().String()
+	}
+	return coverage(v.Type(), opt), nil
+}
+
+func coverage(t reflect.Type, o opts) CoverageReport {
+	r := CoverageReport{}
+	coverStruct(t, nil, &nestedTag{}, o, &r)
+	return r
+}
+
+// coverStruct makes the same decisions as parseStruct about which fields are flagarized and reports them.
+// Fields with invalid tags are skipped, as they are reported by Flagarize.
+func coverStruct(t reflect.Type, path FieldPath, parent *nestedTag, o opts, r *CoverageReport) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := path.Child(field.Name)
+		pos := newFieldPos(o.root, fieldPath, t, field.Name)
+		if isIgnored(field) || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		customFlagarizer := implementsFlagarizer(field.Type) || implementsValueFlagarizer(field.Type) || o.customValue(field.Type) != nil
+		if field.Type.Kind() == reflect.Struct && !customFlagarizer {
+			nested, ok, err := parseNestedTag(field, parent, fieldPath, o.elemSep)
+			if err != nil {
+				continue
+			}
+			if ok {
+				coverStruct(field.Type, fieldPath, nested, o, r)
+				continue
+			}
+		}
+
+		if _, ok := field.Tag.Lookup(flagTagName); !ok {
+			if field.PkgPath != "" || isHelpVar(field) {
+				continue
+			}
+			r.Uncovered = append(r.Uncovered, &UncoveredFieldError{FieldPos: pos})
+			continue
+		}
+		r.Flagarized = append(r.Flagarized, pos)
+		if customFlagarizer {
+			coverShadowed(field.Type, fieldPath, pos.Path, o, r)
+		}
+	}
+}
+
+// coverShadowed reports tagged fields of the type registered by custom Flagarizer as uncovered.
+func coverShadowed(t reflect.Type, path FieldPath, by FieldPath, o opts, r *CoverageReport) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := path.Child(field.Name)
+		if isIgnored(field) {
+			continue
+		}
+		if _, ok := field.Tag.Lookup(flagTagName); ok {
+			r.Uncovered = append(r.Uncovered, &UncoveredFieldError{FieldPos: newFieldPos(o.root, fieldPath, t, field.Name), ShadowedBy: by})
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			coverShadowed(field.Type, fieldPath, by, o, r)
+		}
+	}
+}
+
+// isHelpVar returns true if the field is `<Field>FlagarizeHelp` field providing help for other field.
+func isHelpVar(field reflect.StructField) bool {
+	return strings.HasSuffix(field.Name, "FlagarizeHelp") && field.Type.Kind() == reflect.String
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type shadowingFlagarizer struct {
+	Addr  string `flagarize:"name=addr|help=Addr."`
+	Inner struct {
+		Port int `flagarize:"name=port|help=Port."`
+	}
+	Skipped string `flagarize:"-"`
+}
+
+func (s *shadowingFlagarizer) Flagarize(r flagarize.FlagRegisterer, tag *flagarize.Tag, _ unsafe.Pointer) error {
+	tag.Flag(r).StringVar(&s.Addr)
+	return nil
+}
+
+type coverageWebConfig struct {
+	Listen  string `flagarize:"name=listen|help=Listen."`
+	Timeout string
+}
+
+type coverageConfig struct {
+	Web      coverageWebConfig   `flagarize:"prefix=web."`
+	Server   shadowingFlagarizer `flagarize:"name=server|help=Server."`
+	Name     string              `flagarize:"name=name|help=Name."`
+	Debug    bool
+	Internal string `flagarize:"-"`
+	Ignored  struct {
+		Field string
+	} `flagarize:"-"`
+	Untagged struct {
+		Level string
+	}
+	Content flagarize.PathOrContent
+
+	NameFlagarizeHelp string
+	private           string
+}
+
+func TestCoverage(t *testing.T) {
+	report, err := flagarize.Coverage(&coverageConfig{})
+	testutil.Ok(t, err)
+
+	var flagarized []string
+	for _, f := range report.Flagarized {
+		flagarized = append(flagarized, f.String())
+	}
+	testutil.Equals(t, []string{"coverageConfig.Web.Listen", "coverageConfig.Server", "coverageConfig.Name"}, flagarized)
+
+	var uncovered []string
+	for _, f := range report.Uncovered {
+		uncovered = append(uncovered, f.Error())
+	}
+	testutil.Equals(t, []string{
+		"coverageConfig.Web.Timeout: exported field is not flagarized; add flagarize struct Tag or `flagarize:\"-\"` to ignore it",
+		"coverageConfig.Server.Addr: flagarize struct Tag is ignored, because coverageConfig.Server is registered by custom Flagarizer",
+		"coverageConfig.Server.Inner.Port: flagarize struct Tag is ignored, because coverageConfig.Server is registered by custom Flagarizer",
+		"coverageConfig.Debug: exported field is not flagarized; add flagarize struct Tag or `flagarize:\"-\"` to ignore it",
+		"coverageConfig.Untagged.Level: exported field is not flagarized; add flagarize struct Tag or `flagarize:\"-\"` to ignore it",
+		"coverageConfig.Content: exported field is not flagarized; add flagarize struct Tag or `flagarize:\"-\"` to ignore it",
+	}, uncovered)
+	testutil.Equals(t, "github.com/bwplotka/flagarize_test.coverageWebConfig.Timeout", report.Uncovered[0].Location)
+	testutil.Equals(t, flagarize.FieldPath{"coverageConfig", "Server"}, report.Uncovered[1].ShadowedBy)
+}
+
+func TestFlagarize_Strict(t *testing.T) {
+	type config struct {
+		Name    string `flagarize:"name=name|help=Name."`
+		Skipped string `flagarize:"-"`
+		Debug   bool
+	}
+
+	// Without strict mode, uncovered fields are ignored.
+	testutil.Ok(t, flagarize.Flagarize(newTestKingpin(t), &config{}))
+
+	err := flagarize.Flagarize(newTestKingpin(t), &config{}, flagarize.WithStrict())
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "flagarize: config.Debug: exported field is not flagarized; add flagarize struct Tag or `flagarize:\"-\"` to ignore it", err.Error())
+
+	type coveredConfig struct {
+		Name    string `flagarize:"name=name|help=Name."`
+		Skipped string `flagarize:"-"`
+	}
+	app := newTestKingpin(t)
+	cfg := &coveredConfig{}
+	testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithStrict()))
+	_, err = app.Parse([]string{"--name=a"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, &coveredConfig{Name: "a"}, cfg)
+	testutil.Assert(t, app.GetFlag("skipped") == nil, "ignored field should not register flag")
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"reflect"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// FlagSpec describes flag (or positional argument) that Flagarize registers for the field.
+type FlagSpec struct {
+	Name        string
+	Short       rune
+	EnvVar      string
+	Help        string
+	Default     []string
+	PlaceHolder string
+	Hidden      bool
+	Required    bool
+	Group       string
+	Secret      bool
+	// Deprecated is a deprecation note from `deprecated` struct tag. It is also included in Help.
+	Deprecated string
+	// Arg is true if this is positional argument instead of flag. Only Name, EnvVar, Help, Default, Required,
+	// Secret and Deprecated are set for positional arguments.
+	Arg bool
+	// Command is the full command the flag is registered in e.g "tools compact". Empty for top level flags.
+	Command string
+
+	// Path is a path of the field the flag is registered for.
+	Path FieldPath
+	// Type is Go type of the field e.g "time.Duration".
+	Type string
+
+	Constraints Constraints
+}
+
+// Constraints are constraints of the flag value checked after parse.
+type Constraints struct {
+	Requires  []string
+	Conflicts []string
+	OneOf     string
+
+	Min, Max       string
+	MinLen, MaxLen string
+	Pattern        string
+	NonEmpty       bool
+	Enum           []string
+}
+
+// Describe returns specs of flags that Flagarize would register for the given struct, without registering anything in
+// the application. Flags are registered the same way as by Flagarize (in a temporary application), so the same options
+// should be passed. Custom Flagarizers are invoked on a shallow copy of the struct.
+func Describe(s interface{}, o ...OptFunc) ([]FlagSpec, error) {
+	return describe(kingpin.New("describe", ""), s, o)
+}
+
+// describe flagarizes shallow copy of the struct in the given application and returns specs of flagarized flags.
+func describe(app *kingpin.Application, s interface{}, o []OptFunc) ([]FlagSpec, error) {
+	v, err := structValue(s)
+	if err != nil {
+		return nil, err
+	}
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+
+	var specs []FlagSpec
+	if err := Flagarize(app, c.Interface(), append(o, func(opt *opts) { opt.specs = &specs })...); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+func newFlagSpec(c *kingpin.FlagClause, tag *Tag, path FieldPath, typ reflect.Type) FlagSpec {
+	m := c.Model()
+	return FlagSpec{
+		Name:        m.Name,
+		Short:       m.Short,
+		EnvVar:      m.Envar,
+		Help:        m.Help,
+		Default:     m.Default,
+		PlaceHolder: m.FormatPlaceHolder(),
+		Hidden:      m.Hidden,
+		Required:    m.Required,
+		Group:       tag.Group,
+		Secret:      tag.Secret,
+		Deprecated:  tag.Deprecated,
+		Path:        path,
+		Type:        typ.String(),
+		Constraints: Constraints{
+			Requires:  tag.Requires,
+			Conflicts: tag.Conflicts,
+			OneOf:     tag.OneOf,
+			Min:       tag.Min,
+			Max:       tag.Max,
+			MinLen:    tag.MinLen,
+			MaxLen:    tag.MaxLen,
+			Pattern:   tag.Pattern,
+			NonEmpty:  tag.NonEmpty,
+			Enum:      tag.Enum,
+		},
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+func TestDescribe(t *testing.T) {
+	type webConfig struct {
+		Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|envvar=TIMEOUT|min=1s"`
+		Mode    string        `flagarize:"name=mode|help=Mode.|enum=a|enum=b|short=m|secret=true"`
+	}
+	type config struct {
+		Web    webConfig               `flagarize:"prefix=web.|envprefix=WEB_|group=web"`
+		Config flagarize.PathOrContent `flagarize:"name=config|help=config.|required=true|placeholder=<yaml>"`
+		Peers  []string                `flagarize:"name=peer|help=Peers.|hidden=true|requires=web.mode"`
+	}
+
+	cfg := &config{Peers: []string{"a"}}
+	specs, err := flagarize.Describe(cfg, flagarize.WithValuesAsDefaults())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []flagarize.FlagSpec{
+		{
+			Name: "web.timeout", EnvVar: "WEB_TIMEOUT", Help: "Timeout. (>=1s)", Default: []string{"1m"}, PlaceHolder: "1m",
+			Group: "web", Path: flagarize.FieldPath{"Web", "Timeout"}, Type: "time.Duration",
+			Constraints: flagarize.Constraints{Min: "1s"},
+		},
+		{
+			Name: "web.mode", Short: 'm', Help: "Mode. (one of: a, b)", PlaceHolder: "WEB.MODE", Group: "web", Secret: true,
+			Path: flagarize.FieldPath{"Web", "Mode"}, Type: "string",
+			Constraints: flagarize.Constraints{Enum: []string{"a", "b"}},
+		},
+		{
+			Name: "config-file", Help: "Path to config.", PlaceHolder: "<file-path>",
+			Path: flagarize.FieldPath{"Config"}, Type: "flagarize.PathOrContent",
+		},
+		{
+			Name: "config", Help: "Alternative to 'config-file' flag (lower priority). Content of config.", PlaceHolder: "<content>",
+			Path: flagarize.FieldPath{"Config"}, Type: "flagarize.PathOrContent",
+		},
+		{
+			Name: "peer", Help: "Peers.", Default: []string{"a"}, PlaceHolder: "a", Hidden: true,
+			Path: flagarize.FieldPath{"Peers"}, Type: "[]string",
+			Constraints: flagarize.Constraints{Requires: []string{"web.mode"}},
+		},
+	}, specs)
+
+	// Config is not changed.
+	testutil.Equals(t, &config{Peers: []string{"a"}}, cfg)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// FieldChange is a change of the flagarized field value.
+type FieldChange struct {
+	Path FieldPath
+	Flag string
+	// Old and New are values rendered the way they would be passed as flags (see ValueFormatter). Values of fields
+	// with `secret=true` struct tag are redacted.
+	Old, New []string
+}
+
+// Diff returns changes of values of all flagarized fields between old and new struct of the same type, in declaration
+// order. Values are compared using `Equal(T) bool` method if field type T (or pointer to it) implements it (e.g net.IP
+// or TimeOrDuration). Otherwise values are equal if they are rendered the same way as flags (see ValueFormatter).
+// The same options as passed to Flagarize should be passed, so flag names are the same.
+func Diff(old, new interface{}, o ...OptFunc) ([]FieldChange, error) {
+	ov, err := structValue(old)
+	if err != nil {
+		return nil, err
+	}
+	nv, err := structValue(new)
+	if err != nil {
+		return nil, err
+	}
+	if ov.Type() != nv.Type() {
+		return nil, errors.Errorf("flagarize: cannot diff different types %s and %s", ov.Type(), nv.Type())
+	}
+
+	opt := opts{elemSep: "|"}.apply(o...)
+	oldFields, err := dumpFields(ov, opt, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "flagarize")
+	}
+	newFields, err := dumpFields(nv, opt, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "flagarize")
+	}
+
+	var changes []FieldChange
+	for i, of := range oldFields {
+		nf := newFields[i]
+		if equalValues(of.value, nf.value, opt) {
+			continue
+		}
+		changes = append(changes, FieldChange{Path: of.path, Flag: of.tag.Name, Old: of.values, New: nf.values})
+	}
+	return changes, nil
+}
+
+// equalValues returns true if values of the same type are equal.
+func equalValues(a, b reflect.Value, o opts) bool {
+	if eq, ok := invokeEqual(a, b); ok {
+		return eq
+	}
+	return equalStrings(o.formatValue(a), o.formatValue(b))
+}
+
+// invokeEqual invokes `Equal(T) bool` or `Equal(*T) bool` method of T or *T if implemented.
+func invokeEqual(a, b reflect.Value) (eq bool, ok bool) {
+	if !a.CanInterface() {
+		return false, false
+	}
+	for _, recv := range []reflect.Value{a, addr(a)} {
+		if !recv.IsValid() {
+			continue
+		}
+		m := recv.MethodByName("Equal")
+		if !m.IsValid() || m.Type().NumIn() != 1 || m.Type().NumOut() != 1 || m.Type().Out(0).Kind() != reflect.Bool {
+			continue
+		}
+		switch in := m.Type().In(0); {
+		case in == a.Type():
+			if a.Kind() == reflect.Ptr && (a.IsNil() || b.IsNil()) {
+				return a.IsNil() == b.IsNil(), true
+			}
+			return m.Call([]reflect.Value{b})[0].Bool(), true
+		case in == reflect.PtrTo(a.Type()) && addr(b).IsValid():
+			return m.Call([]reflect.Value{addr(b)})[0].Bool(), true
+		}
+	}
+	return false, false
+}
+
+func addr(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		return reflect.Value{}
+	}
+	return v.Addr()
+}
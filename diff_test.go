@@ -0,0 +1,92 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type version struct{ major, minor int }
+
+func (v *version) Set(string) error { return nil }
+
+func (v version) String() string { return "" }
+
+// Equal ignores minor version.
+func (v version) Equal(o version) bool { return v.major == o.major }
+
+func TestDiff(t *testing.T) {
+	type webConfig struct {
+		URL      *url.URL `flagarize:"name=url|help=URL."`
+		Password string   `flagarize:"name=password|help=Password.|secret=true"`
+	}
+	type config struct {
+		Web     webConfig                `flagarize:"prefix=web."`
+		IP      net.IP                   `flagarize:"name=ip|help=IP."`
+		Regex   flagarize.Regexp         `flagarize:"name=regex|help=Regex."`
+		Since   flagarize.TimeOrDuration `flagarize:"name=since|help=Since."`
+		Peers   []string                 `flagarize:"name=peer|help=Peers."`
+		Version version                  `flagarize:"name=version|help=Version."`
+	}
+
+	newConfig := func() *config {
+		u, err := url.Parse("http://localhost:80")
+		testutil.Ok(t, err)
+		since := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+		c := &config{
+			Web:     webConfig{URL: u, Password: "a"},
+			IP:      net.IPv4(127, 0, 0, 1),
+			Since:   flagarize.TimeOrDuration{Time: &since},
+			Peers:   []string{"a", "b"},
+			Version: version{major: 1, minor: 1},
+		}
+		testutil.Ok(t, c.Regex.Set("a+"))
+		return c
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		old, new := newConfig(), newConfig()
+		new.IP = new.IP.To4()
+		since := new.Since.Time.In(time.FixedZone("CET", 3600))
+		new.Since.Time = &since
+		new.Version.minor = 2
+
+		changes, err := flagarize.Diff(old, new)
+		testutil.Ok(t, err)
+		testutil.Equals(t, 0, len(changes))
+	})
+	t.Run("changed", func(t *testing.T) {
+		old, new := newConfig(), newConfig()
+		new.Web.URL.Host = "localhost:81"
+		new.Web.Password = "b"
+		new.IP = net.IPv4(127, 0, 0, 2)
+		testutil.Ok(t, new.Regex.Set("b+"))
+		d := time.Hour
+		new.Since = flagarize.TimeOrDuration{Dur: &d}
+		new.Peers = new.Peers[:1]
+		new.Version.major = 2
+
+		changes, err := flagarize.Diff(old, new)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []flagarize.FieldChange{
+			{Path: flagarize.FieldPath{"Web", "URL"}, Flag: "web.url", Old: []string{"http://localhost:80"}, New: []string{"http://localhost:81"}},
+			{Path: flagarize.FieldPath{"Web", "Password"}, Flag: "web.password", Old: []string{"<redacted>"}, New: []string{"<redacted>"}},
+			{Path: flagarize.FieldPath{"IP"}, Flag: "ip", Old: []string{"127.0.0.1"}, New: []string{"127.0.0.2"}},
+			{Path: flagarize.FieldPath{"Regex"}, Flag: "regex", Old: []string{"a+"}, New: []string{"b+"}},
+			{Path: flagarize.FieldPath{"Since"}, Flag: "since", Old: []string{"2020-01-01T10:00:00Z"}, New: []string{"1h0m0s"}},
+			{Path: flagarize.FieldPath{"Peers"}, Flag: "peer", Old: []string{"a", "b"}, New: []string{"a"}},
+			{Path: flagarize.FieldPath{"Version"}, Flag: "version", Old: []string{""}, New: []string{""}},
+		}, changes)
+	})
+	t.Run("different types", func(t *testing.T) {
+		_, err := flagarize.Diff(&config{}, &webConfig{})
+		testutil.NotOk(t, err)
+	})
+}
@@ -0,0 +1,418 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// DocFormat is a format of the documentation generated by WriteDocs.
+type DocFormat string
+
+const (
+	// DocMarkdown renders Markdown with flags and positional arguments in tables.
+	DocMarkdown DocFormat = "markdown"
+	// DocMan renders man page using man(7) macros.
+	DocMan DocFormat = "man"
+	// DocRST renders reStructuredText.
+	DocRST DocFormat = "rst"
+)
+
+// WriteDocs writes documentation of flags, positional arguments and commands that Flagarize would register for the
+// given struct in the application with the given name and help. Flags are registered the same way as by Describe, so
+// the same options should be passed. Documentation includes defaults, environment variables, help groups, constraints,
+// deprecation notes and commands. Hidden flags and commands are omitted and defaults of secret fields are redacted.
+// Flags registered by options (e.g --config.file) are included too.
+//
+// It is meant to be invoked by `go generate` e.g from the `go run` program, so the documentation is always in sync
+// with the code.
+func WriteDocs(w io.Writer, format DocFormat, name, help string, s interface{}, o ...OptFunc) error {
+	app := kingpin.New(name, help)
+	specs, err := describe(app, s, o)
+	if err != nil {
+		return err
+	}
+	d := newDoc(app, specs)
+
+	var buf bytes.Buffer
+	switch format {
+	case DocMarkdown:
+		d.markdown(&buf)
+	case DocMan:
+		d.man(&buf)
+	case DocRST:
+		d.rst(&buf)
+	default:
+		return errors.Errorf("flagarize: unsupported doc format %q; only %q, %q and %q are supported", format, DocMarkdown, DocMan, DocRST)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// appDoc is a documentation of the application, independent of the format.
+type appDoc struct {
+	name, help string
+	sections   []docSection
+}
+
+// docSection documents flags and arguments of the application (top level section) or command.
+type docSection struct {
+	// command is the full command e.g "tools compact". Empty for the top level section.
+	command string
+	help    string
+	flags   []docEntry
+	args    []docEntry
+}
+
+type docEntry struct {
+	FlagSpec
+	// usage is the flag or argument as passed in the command line e.g "--web.timeout=DURATION" or "<files>...".
+	usage string
+	// isBool is true for bool flags, that do not take value and can be negated with --no- prefix.
+	isBool bool
+	// repeatable is true for flags and arguments that can be specified many times.
+	repeatable bool
+	// files is true for flags and arguments which values are file names (see isFileValue).
+	files bool
+}
+
+func newDoc(app *kingpin.Application, specs []FlagSpec) *appDoc {
+	byKey := map[string]FlagSpec{}
+	for _, s := range specs {
+		byKey[docKey(s.Command, s.Name, s.Arg)] = s
+	}
+
+	m := app.Model()
+	d := &appDoc{name: m.Name, help: m.Help}
+	d.sections = append(d.sections, newDocSection("", "", m.FlagGroupModel, m.ArgGroupModel, byKey))
+	walkCommands(m.CmdGroupModel, func(c *kingpin.CmdModel) {
+		d.sections = append(d.sections, newDocSection(c.FullCommand, c.Help, c.FlagGroupModel, c.ArgGroupModel, byKey))
+	})
+	return d
+}
+
+// walkCommands invokes fn for all not hidden commands, parents before their subcommands.
+func walkCommands(g *kingpin.CmdGroupModel, fn func(*kingpin.CmdModel)) {
+	for _, c := range g.Commands {
+		if c.Hidden {
+			continue
+		}
+		fn(c)
+		walkCommands(c.CmdGroupModel, fn)
+	}
+}
+
+func docKey(command, name string, arg bool) string {
+	return fmt.Sprintf("%s\x00%s\x00%v", command, name, arg)
+}
+
+func newDocSection(command, help string, flags *kingpin.FlagGroupModel, args *kingpin.ArgGroupModel, byKey map[string]FlagSpec) docSection {
+	s := docSection{command: command, help: help}
+	for _, f := range flags.Flags {
+		if f.Hidden || f.Name == "help" {
+			continue
+		}
+		spec, ok := byKey[docKey(command, f.Name, false)]
+		if !ok {
+			// Flag registered by option e.g --config.file.
+			spec = FlagSpec{Name: f.Name, Short: f.Short, EnvVar: f.Envar, Help: f.Help, Default: f.Default, PlaceHolder: f.FormatPlaceHolder(), Required: f.Required, Command: command}
+		}
+		usage := "--" + f.Name
+		if !f.IsBoolFlag() {
+			placeHolder := f.FormatPlaceHolder()
+			if spec.Secret && f.PlaceHolder == "" {
+				// Placeholder defaults to the default value.
+				placeHolder = strings.ToUpper(f.Name)
+			}
+			usage += "=" + placeHolder
+		}
+		if f.Short != 0 {
+			usage = fmt.Sprintf("-%c, %s", f.Short, usage)
+		}
+		s.flags = append(s.flags, docEntry{FlagSpec: spec, usage: usage, isBool: f.IsBoolFlag(), repeatable: isCumulative(f.Value), files: isFileValue(spec, f.Value)})
+	}
+	for _, a := range args.Args {
+		spec, ok := byKey[docKey(command, a.Name, true)]
+		if !ok {
+			spec = FlagSpec{Name: a.Name, EnvVar: a.Envar, Help: a.Help, Default: a.Default, Required: a.Required, Arg: true, Command: command}
+		}
+		usage := fmt.Sprintf("<%s>", a.Name)
+		if isCumulative(a.Value) {
+			usage += "..."
+		}
+		s.args = append(s.args, docEntry{FlagSpec: spec, usage: usage, repeatable: isCumulative(a.Value), files: isFileValue(spec, a.Value)})
+	}
+	return s
+}
+
+// groups returns flags grouped by help group. Flags without group are first, other groups are in the order of the first
+// flag.
+func (s docSection) groups() (names []string, flags map[string][]docEntry) {
+	flags = map[string][]docEntry{}
+	names = []string{""}
+	for _, f := range s.flags {
+		if _, ok := flags[f.Group]; !ok && f.Group != "" {
+			names = append(names, f.Group)
+		}
+		flags[f.Group] = append(flags[f.Group], f)
+	}
+	return names, flags
+}
+
+// defaults returns rendered default of the flag or argument. Defaults of secret fields are redacted.
+func (e docEntry) defaults() string {
+	if len(e.Default) == 0 {
+		return ""
+	}
+	if e.Secret {
+		return redacted
+	}
+	return strings.Join(e.Default, ", ")
+}
+
+// notes returns constraints of the flag that are not part of the help already.
+func (e docEntry) notes() []string {
+	var n []string
+	if e.Required {
+		n = append(n, "Required.")
+	}
+	if len(e.Constraints.Requires) > 0 {
+		n = append(n, fmt.Sprintf("Requires %s.", flagList(e.Constraints.Requires)))
+	}
+	if len(e.Constraints.Conflicts) > 0 {
+		n = append(n, fmt.Sprintf("Conflicts with %s.", flagList(e.Constraints.Conflicts)))
+	}
+	if e.Constraints.OneOf != "" {
+		n = append(n, fmt.Sprintf("At most one flag of %q group can be set.", e.Constraints.OneOf))
+	}
+	return n
+}
+
+func flagList(names []string) string {
+	f := make([]string, 0, len(names))
+	for _, n := range names {
+		f = append(f, "--"+n)
+	}
+	return strings.Join(f, ", ")
+}
+
+func (d *appDoc) markdown(w io.Writer) {
+	fmt.Fprintf(w, "# %s\n", d.name)
+	if d.help != "" {
+		fmt.Fprintf(w, "\n%s\n", d.help)
+	}
+	for i, s := range d.sections {
+		level := "##"
+		if s.command != "" {
+			if i == 1 {
+				fmt.Fprint(w, "\n## Commands\n")
+			}
+			fmt.Fprintf(w, "\n### `%s %s`\n", d.name, s.command)
+			if s.help != "" {
+				fmt.Fprintf(w, "\n%s\n", s.help)
+			}
+			level = "####"
+		}
+		names, groups := s.groups()
+		if len(s.flags) > 0 {
+			fmt.Fprintf(w, "\n%s Flags\n", level)
+		}
+		for _, g := range names {
+			if len(groups[g]) == 0 {
+				continue
+			}
+			if g != "" {
+				fmt.Fprintf(w, "\n%s# Group `%s`\n", level, g)
+			}
+			fmt.Fprint(w, "\n| Flag | Env | Default | Description |\n|------|-----|---------|-------------|\n")
+			for _, f := range groups[g] {
+				markdownRow(w, f)
+			}
+		}
+		if len(s.args) > 0 {
+			fmt.Fprintf(w, "\n%s Arguments\n", level)
+			fmt.Fprint(w, "\n| Argument | Env | Default | Description |\n|----------|-----|---------|-------------|\n")
+			for _, a := range s.args {
+				markdownRow(w, a)
+			}
+		}
+	}
+}
+
+func markdownRow(w io.Writer, e docEntry) {
+	code := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + markdownEscape(s) + "`"
+	}
+	desc := strings.Join(append([]string{e.Help}, e.notes()...), " ")
+	fmt.Fprintf(w, "| %s | %s | %s | %s |\n", code(e.usage), code(e.EnvVar), code(e.defaults()), markdownEscape(strings.TrimSpace(desc)))
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func (d *appDoc) man(w io.Writer) {
+	fmt.Fprintf(w, ".TH %s 1\n.SH NAME\n%s", manEscape(strings.ToUpper(d.name)), manEscape(d.name))
+	if d.help != "" {
+		fmt.Fprintf(w, " \\- %s", manEscape(strings.SplitN(d.help, "\n", 2)[0]))
+	}
+	fmt.Fprintf(w, "\n.SH SYNOPSIS\n\\fB%s\\fR [\\fIflags\\fR]", manEscape(d.name))
+	if len(d.sections) > 1 {
+		fmt.Fprint(w, " \\fIcommand\\fR [\\fIflags\\fR]")
+	}
+	for _, a := range d.sections[0].args {
+		fmt.Fprintf(w, " %s", manEscape(a.usage))
+	}
+	fmt.Fprint(w, "\n")
+	if d.help != "" {
+		fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", manEscape(d.help))
+	}
+	for i, s := range d.sections {
+		if s.command != "" {
+			if i == 1 {
+				fmt.Fprint(w, ".SH COMMANDS\n")
+			}
+			fmt.Fprintf(w, ".SS %s\n", manEscape(s.command))
+			if s.help != "" {
+				fmt.Fprintf(w, "%s\n", manEscape(s.help))
+			}
+		} else if len(s.flags) > 0 {
+			fmt.Fprint(w, ".SH FLAGS\n")
+		}
+		names, groups := s.groups()
+		for _, g := range names {
+			if len(groups[g]) == 0 {
+				continue
+			}
+			if g != "" {
+				fmt.Fprintf(w, ".PP\n\\fB%s flags:\\fR\n", manEscape(g))
+			}
+			for _, f := range groups[g] {
+				manEntry(w, f)
+			}
+		}
+		if len(s.args) > 0 {
+			if s.command == "" {
+				fmt.Fprint(w, ".SH ARGUMENTS\n")
+			} else {
+				fmt.Fprint(w, ".PP\n\\fBArguments:\\fR\n")
+			}
+			for _, a := range s.args {
+				manEntry(w, a)
+			}
+		}
+	}
+}
+
+func manEntry(w io.Writer, e docEntry) {
+	fmt.Fprintf(w, ".TP\n\\fB%s\\fR\n", manEscape(e.usage))
+	if e.Help != "" {
+		fmt.Fprintf(w, "%s\n", manEscape(e.Help))
+	}
+	var details []string
+	if d := e.defaults(); d != "" {
+		details = append(details, fmt.Sprintf("Default: %s.", manEscape(d)))
+	}
+	if e.EnvVar != "" {
+		details = append(details, fmt.Sprintf("Environment variable: \\fB%s\\fR.", manEscape(e.EnvVar)))
+	}
+	for _, n := range e.notes() {
+		details = append(details, manEscape(n))
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(w, ".br\n%s\n", strings.Join(details, " "))
+	}
+}
+
+// manEscape escapes text for roff, so it's not interpreted as escapes or requests.
+func manEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *appDoc) rst(w io.Writer) {
+	rstHeading(w, d.name, '=')
+	if d.help != "" {
+		fmt.Fprintf(w, "%s\n\n", rstEscape(d.help))
+	}
+	for i, s := range d.sections {
+		sub, subsub := byte('-'), byte('~')
+		if s.command != "" {
+			if i == 1 {
+				rstHeading(w, "Commands", '-')
+			}
+			rstHeading(w, d.name+" "+s.command, '~')
+			if s.help != "" {
+				fmt.Fprintf(w, "%s\n\n", rstEscape(s.help))
+			}
+			sub, subsub = '^', '"'
+		}
+		names, groups := s.groups()
+		if len(s.flags) > 0 {
+			rstHeading(w, "Flags", sub)
+		}
+		for _, g := range names {
+			if len(groups[g]) == 0 {
+				continue
+			}
+			if g != "" {
+				rstHeading(w, fmt.Sprintf("Group %s", g), subsub)
+			}
+			for _, f := range groups[g] {
+				rstEntry(w, f)
+			}
+		}
+		if len(s.args) > 0 {
+			rstHeading(w, "Arguments", sub)
+			for _, a := range s.args {
+				rstEntry(w, a)
+			}
+		}
+	}
+}
+
+func rstHeading(w io.Writer, title string, c byte) {
+	title = rstEscape(title)
+	fmt.Fprintf(w, "%s\n%s\n\n", title, strings.Repeat(string(c), len([]rune(title))))
+}
+
+func rstEntry(w io.Writer, e docEntry) {
+	fmt.Fprintf(w, "``%s``\n", e.usage)
+	if e.Help != "" {
+		fmt.Fprintf(w, "   %s\n", strings.Replace(rstEscape(e.Help), "\n", "\n   ", -1))
+	}
+	var details []string
+	if d := e.defaults(); d != "" {
+		details = append(details, fmt.Sprintf("Default: ``%s``.", d))
+	}
+	if e.EnvVar != "" {
+		details = append(details, fmt.Sprintf("Environment variable: ``%s``.", e.EnvVar))
+	}
+	for _, n := range e.notes() {
+		details = append(details, rstEscape(n))
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(w, "\n   %s\n", strings.Join(details, " "))
+	}
+	fmt.Fprint(w, "\n")
+}
+
+func rstEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "`", "\\`", "|", `\|`, "_", `\_`).Replace(s)
+}
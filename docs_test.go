@@ -0,0 +1,99 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type docsWebConfig struct {
+	Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|envvar=TIMEOUT|min=1s"`
+	Token   string        `flagarize:"name=token|help=Token.|default=abc|secret=true|conflicts=web.timeout"`
+}
+
+type docsCompactConfig struct {
+	DryRun bool     `flagarize:"name=dry-run|help=Dry run."`
+	Blocks []string `flagarize:"arg=blocks|help=Blocks to compact."`
+}
+
+type docsConfig struct {
+	Web     docsWebConfig     `flagarize:"prefix=web.|envprefix=WEB_|group=web"`
+	Listen  string            `flagarize:"name=listen|help=Listen address.|required=true|deprecated=use --web.listen instead"`
+	Hidden  string            `flagarize:"name=hidden|help=Hidden.|hidden=true"`
+	Compact docsCompactConfig `flagarize:"cmd=compact|help=Compact blocks."`
+}
+
+func TestWriteDocs(t *testing.T) {
+	t.Run("markdown", func(t *testing.T) {
+		var b bytes.Buffer
+		testutil.Ok(t, flagarize.WriteDocs(&b, flagarize.DocMarkdown, "app", "My app.", &docsConfig{}))
+		testutil.Equals(t, "# app\n"+
+			"\n"+
+			"My app.\n"+
+			"\n"+
+			"## Flags\n"+
+			"\n"+
+			"| Flag | Env | Default | Description |\n"+
+			"|------|-----|---------|-------------|\n"+
+			"| `--listen=LISTEN` |  |  | Listen address. (deprecated: use --web.listen instead) Required. |\n"+
+			"\n"+
+			"### Group `web`\n"+
+			"\n"+
+			"| Flag | Env | Default | Description |\n"+
+			"|------|-----|---------|-------------|\n"+
+			"| `--web.timeout=1m` | `WEB_TIMEOUT` | `1m` | Timeout. (>=1s) |\n"+
+			"| `--web.token=WEB.TOKEN` |  | `<redacted>` | Token. Conflicts with --web.timeout. |\n"+
+			"\n"+
+			"## Commands\n"+
+			"\n"+
+			"### `app compact`\n"+
+			"\n"+
+			"Compact blocks.\n"+
+			"\n"+
+			"#### Flags\n"+
+			"\n"+
+			"| Flag | Env | Default | Description |\n"+
+			"|------|-----|---------|-------------|\n"+
+			"| `--dry-run` |  |  | Dry run. |\n"+
+			"\n"+
+			"#### Arguments\n"+
+			"\n"+
+			"| Argument | Env | Default | Description |\n"+
+			"|----------|-----|---------|-------------|\n"+
+			"| `<blocks>...` |  |  | Blocks to compact. |\n", b.String())
+	})
+	t.Run("man", func(t *testing.T) {
+		var b bytes.Buffer
+		testutil.Ok(t, flagarize.WriteDocs(&b, flagarize.DocMan, "app", "My app.", &docsConfig{}, flagarize.WithConfigFile()))
+		for _, exp := range []string{
+			".TH APP 1\n.SH NAME\napp \\- My app.\n",
+			".TP\n\\fB\\-\\-config.file=<path>\\fR\n",
+			".TP\n\\fB\\-\\-web.timeout=1m\\fR\nTimeout. (>=1s)\n.br\nDefault: 1m. Environment variable: \\fBWEB_TIMEOUT\\fR.\n",
+			".SH COMMANDS\n.SS compact\nCompact blocks.\n",
+		} {
+			testutil.Assert(t, strings.Contains(b.String(), exp), "expected %q in:\n%s", exp, b.String())
+		}
+		testutil.Assert(t, !strings.Contains(b.String(), "hidden"), "hidden flag rendered")
+	})
+	t.Run("rst", func(t *testing.T) {
+		var b bytes.Buffer
+		testutil.Ok(t, flagarize.WriteDocs(&b, flagarize.DocRST, "app", "My app.", &docsConfig{}))
+		for _, exp := range []string{
+			"app\n===\n\nMy app.\n\nFlags\n-----\n",
+			"Group web\n~~~~~~~~~\n\n``--web.timeout=1m``\n   Timeout. (>=1s)\n\n   Default: ``1m``. Environment variable: ``WEB_TIMEOUT``.\n",
+			"app compact\n~~~~~~~~~~~\n\nCompact blocks.\n\nFlags\n^^^^^\n",
+		} {
+			testutil.Assert(t, strings.Contains(b.String(), exp), "expected %q in:\n%s", exp, b.String())
+		}
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		testutil.NotOk(t, flagarize.WriteDocs(&bytes.Buffer{}, "html", "app", "", &docsConfig{}))
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+const secretStructTagKey = "secret"
+
+// redacted is rendered instead of values of secret fields.
+const redacted = "<redacted>"
+
+// DumpFormat is a format of the config dump.
+type DumpFormat string
+
+const (
+	// DumpJSON renders JSON object with flag names as keys. Values of repeatable flags are arrays.
+	DumpJSON DumpFormat = "json"
+	// DumpEnv renders `KEY=value` lines with environment variable names. For fields without envvar, flag name in the
+	// upper snake case is used. Values of repeatable flags are separated by new lines (as kingpin expects).
+	DumpEnv DumpFormat = "env"
+	// DumpArgs renders command line flags, one per line.
+	DumpArgs DumpFormat = "args"
+	// DumpTable renders table with field paths, flag names and values.
+	DumpTable DumpFormat = "table"
+)
+
+// dumpedField is a flagarized field rendered for the dump.
+type dumpedField struct {
+	path   FieldPath
+	tag    *Tag
+	value  reflect.Value
+	values []string
+	// repeatable is true for slices and maps.
+	repeatable bool
+	boolean    bool
+}
+
+// Dump writes values of all flagarized fields of the given struct in the given format. Values are rendered the way
+// they would be passed as flags (see ValueFormatter). Values of fields with `secret=true` struct tag are redacted.
+// The same options as passed to Flagarize should be passed, so flag names are the same.
+func Dump(w io.Writer, s interface{}, format DumpFormat, o ...OptFunc) error {
+	v, err := structValue(s)
+	if err != nil {
+		return err
+	}
+	fields, err := dumpFields(v, opts{elemSep: "|"}.apply(o...), true)
+	if err != nil {
+		return errors.Wrap(err, "flagarize")
+	}
+
+	switch format {
+	case DumpJSON:
+		return dumpJSON(w, fields)
+	case DumpEnv:
+		for _, f := range fields {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", envName(f.tag), quoteEnvValue(strings.Join(f.values, "\n"))); err != nil {
+				return err
+			}
+		}
+		return nil
+	case DumpArgs:
+		for _, f := range fields {
+			for _, a := range f.args() {
+				if _, err := fmt.Fprintln(w, a); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case DumpTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FIELD\tFLAG\tVALUE")
+		for _, f := range fields {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", f.path, flagOrArg(f.tag), strings.Join(f.values, ","))
+		}
+		return tw.Flush()
+	default:
+		return errors.Errorf("flagarize: not supported dump format %q", format)
+	}
+}
+
+// dumpFields returns all flagarized fields with rendered values. Values of secret fields are redacted if redact is true.
+func dumpFields(v reflect.Value, o opts, redact bool) ([]dumpedField, error) {
+	var fields []dumpedField
+	if err := walkStruct(v, nil, &nestedTag{}, o, func(f walkedField) error {
+		if f.tag == nil {
+			return nil
+		}
+		k := f.value.Kind()
+		d := dumpedField{path: f.path, tag: f.tag, value: f.value, repeatable: k == reflect.Slice || k == reflect.Map, boolean: k == reflect.Bool}
+		d.values = o.formatValue(f.value)
+		if len(d.values) == 0 && !d.repeatable {
+			d.values = formatZeroValue(f.value)
+		}
+		if redact && f.tag.Secret {
+			for i := range d.values {
+				d.values[i] = redacted
+			}
+		}
+		fields = append(fields, d)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// args returns command line flags that set the value of the field. For positional arguments, values are returned.
+func (f dumpedField) args() []string {
+	if f.tag.Arg {
+		return append([]string(nil), f.values...)
+	}
+	// Redacted bool is rendered as value, so it's not mistaken for false.
+	if f.boolean && f.values[0] != redacted {
+		if f.values[0] == "true" {
+			return []string{"--" + f.tag.Name}
+		}
+		return []string{"--no-" + f.tag.Name}
+	}
+	ret := make([]string, 0, len(f.values))
+	for _, v := range f.values {
+		ret = append(ret, fmt.Sprintf("--%s=%s", f.tag.Name, v))
+	}
+	return ret
+}
+
+func dumpJSON(w io.Writer, fields []dumpedField) error {
+	// Render object manually to keep the declaration order.
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+
+	b.WriteString("{")
+	for i, f := range fields {
+		var v interface{} = f.values
+		if !f.repeatable {
+			v = f.values[0]
+		} else if f.values == nil {
+			v = []string{}
+		}
+		b.WriteString("\n  ")
+		if err := enc.Encode(f.tag.Name); err != nil {
+			return errors.Wrapf(err, "marshal %s", f.path)
+		}
+		b.Truncate(b.Len() - 1)
+		b.WriteString(": ")
+		if err := enc.Encode(v); err != nil {
+			return errors.Wrapf(err, "marshal %s", f.path)
+		}
+		b.Truncate(b.Len() - 1)
+		if i < len(fields)-1 {
+			b.WriteString(",")
+		}
+	}
+	b.WriteString("\n}\n")
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// envName returns environment variable name of the flag or flag name in the upper snake case if not specified.
+func envName(t *Tag) string {
+	if t.EnvName != "" {
+		return t.EnvName
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, t.Name)
+}
+
+// quoteEnvValue quotes value if it cannot be used in the env file as it is.
+func quoteEnvValue(v string) string {
+	if strings.ContainsAny(v, " \t\r\n\"'#$\\`") {
+		return strconv.Quote(v)
+	}
+	return v
+}
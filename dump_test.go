@@ -0,0 +1,111 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type dumpWebConfig struct {
+	Timeout  time.Duration `flagarize:"name=timeout|help=Timeout.|envvar=TIMEOUT"`
+	Password string        `flagarize:"name=password|help=Password.|secret=true"`
+	Insecure bool          `flagarize:"name=insecure|help=Insecure.|secret=true"`
+}
+
+type dumpConfig struct {
+	Web    dumpWebConfig     `flagarize:"prefix=web.|envprefix=WEB_"`
+	Debug  bool              `flagarize:"name=debug|help=Debug."`
+	Name   string            `flagarize:"name=name|help=Name."`
+	Peers  []string          `flagarize:"name=peer|help=Peers."`
+	Labels map[string]string `flagarize:"name=label|help=Labels."`
+	Regex  flagarize.Regexp  `flagarize:"name=regex|help=Regex."`
+
+	NotFlag string
+}
+
+func TestDump(t *testing.T) {
+	cfg := &dumpConfig{
+		Web:     dumpWebConfig{Timeout: 5 * time.Minute, Password: "hunter2", Insecure: true},
+		Name:    "some name",
+		Peers:   []string{"a", "b"},
+		Labels:  map[string]string{"b": "2", "a": "1"},
+		NotFlag: "not a flag",
+	}
+	testutil.Ok(t, cfg.Regex.Set("a.*"))
+
+	for _, tcase := range []struct {
+		format   flagarize.DumpFormat
+		expected string
+	}{
+		{
+			format: flagarize.DumpJSON,
+			expected: `{
+  "web.timeout": "5m0s",
+  "web.password": "<redacted>",
+  "web.insecure": "<redacted>",
+  "debug": "false",
+  "name": "some name",
+  "peer": ["a","b"],
+  "label": ["a=1","b=2"],
+  "regex": "a.*"
+}
+`,
+		},
+		{
+			format: flagarize.DumpEnv,
+			expected: `WEB_TIMEOUT=5m0s
+WEB_PASSWORD=<redacted>
+WEB_INSECURE=<redacted>
+DEBUG=false
+NAME="some name"
+PEER="a\nb"
+LABEL="a=1\nb=2"
+REGEX=a.*
+`,
+		},
+		{
+			format: flagarize.DumpArgs,
+			expected: `--web.timeout=5m0s
+--web.password=<redacted>
+--web.insecure=<redacted>
+--no-debug
+--name=some name
+--peer=a
+--peer=b
+--label=a=1
+--label=b=2
+--regex=a.*
+`,
+		},
+		{
+			format: flagarize.DumpTable,
+			expected: `FIELD         FLAG            VALUE
+Web.Timeout   --web.timeout   5m0s
+Web.Password  --web.password  <redacted>
+Web.Insecure  --web.insecure  <redacted>
+Debug         --debug         false
+Name          --name          some name
+Peers         --peer          a,b
+Labels        --label         a=1,b=2
+Regex         --regex         a.*
+`,
+		},
+	} {
+		t.Run(string(tcase.format), func(t *testing.T) {
+			b := &bytes.Buffer{}
+			testutil.Ok(t, flagarize.Dump(b, cfg, tcase.format))
+			testutil.Equals(t, tcase.expected, b.String())
+		})
+	}
+	t.Run("wrong format", func(t *testing.T) {
+		err := flagarize.Dump(&bytes.Buffer{}, cfg, "yaml")
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: not supported dump format \"yaml\"", err.Error())
+	})
+}
@@ -0,0 +1,398 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const envFileFlagName = "env-file"
+
+// WithEnvFile makes values of environment variables of flags (see `envvar` struct tag key) to be loaded also from the
+// given dotenv file, if it exists. It can be specified many times; later files override earlier ones. Real environment
+// variables have priority over the env file, and env file has priority over the config file (see WithConfigFile).
+//
+// Supported dotenv syntax: `KEY=value` lines with optional `export ` prefix, `#` comments, single quoted (literal)
+// values, double quoted values with escapes (\n, \t, \r, \", \\, \$) spanning many lines and `${VAR}` or `$VAR`
+// interpolation of environment variables and variables defined earlier in the file (except in single quotes).
+// Values of repeatable flags are separated by new lines, the same as for environment variables.
+// It requires KingpinRegistry to be *kingpin.Application or *kingpin.CmdClause.
+func WithEnvFile(path string) OptFunc {
+	return func(opt *opts) { opt.envFiles = append(opt.envFiles, path) }
+}
+
+// WithEnvFileFlag registers --env-file=<path> flag with the path to the dotenv file, that has to exist if specified.
+// It has priority over files given by WithEnvFile. See WithEnvFile for details.
+func WithEnvFileFlag() OptFunc { return func(opt *opts) { opt.envFileFlag = true } }
+
+// envFile loads environment variables of flags from dotenv files. It is also kingpin.Value of the --env-file flag.
+type envFile struct {
+	paths    []string
+	flagPath string
+	flags    []*fileField
+	// elements are parse elements added for values from the env file on the last parse.
+	elements sourceElements
+	// sources are elements added by all sources installed together with the env file.
+	sources elementSources
+}
+
+// installEnvFile installs loading of the env files in the registry. If withFlag is true, --env-file flag is registered
+// unless it was already registered.
+func installEnvFile(r KingpinRegistry, withFlag bool) (*envFile, error) {
+	if f := r.GetFlag(envFileFlagName); withFlag && f != nil {
+		e, ok := f.Model().Value.(*envFile)
+		if !ok {
+			return nil, errors.Errorf("env file requires --%s flag, but it was already registered", envFileFlagName)
+		}
+		return e, nil
+	}
+
+	e := &envFile{elements: sourceElements{}}
+	if err := addPreAction(r, e.apply); err != nil {
+		return nil, errors.Wrap(err, "env file")
+	}
+	if withFlag {
+		r.Flag(envFileFlagName, "Path to dotenv file with environment variables of flags. Real environment variables have priority over it.").
+			PlaceHolder("<path>").SetValue(e)
+	}
+	return e, nil
+}
+
+// Set implements kingpin.Value.
+func (e *envFile) Set(path string) error {
+	e.flagPath = path
+	return nil
+}
+
+// String implements kingpin.Value.
+func (e *envFile) String() string { return e.flagPath }
+
+// add adds flags with environment variables, so they can be loaded from the env file. It's noop for nil envFile.
+func (e *envFile) add(fieldValue reflect.Value, flags ...*kingpin.FlagClause) {
+	if e == nil {
+		return
+	}
+	for _, f := range flags {
+		if f.Model().Envar == "" {
+			continue
+		}
+		ff := &fileField{flag: f}
+		if len(flags) == 1 {
+			ff.value = fieldValue
+		}
+		e.flags = append(e.flags, ff)
+	}
+}
+
+// envValue is a value of the variable from the env file.
+type envValue struct {
+	value    string
+	location string
+}
+
+// load returns variables from all env files.
+func (e *envFile) load() (map[string]envValue, error) {
+	vars := map[string]envValue{}
+	paths := e.paths
+	if e.flagPath != "" {
+		paths = append(append([]string(nil), paths...), e.flagPath)
+	}
+	for _, p := range paths {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			if os.IsNotExist(err) && p != e.flagPath {
+				continue
+			}
+			return nil, errors.Wrap(err, "read env file")
+		}
+		if err := parseEnvFile(b, p, vars); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+// apply sets values from the env file for flags that were not set by flag nor real environment variable. It runs
+// before required flags are checked, so env file can provide values of required flags.
+func (e *envFile) apply(ctx *kingpin.ParseContext) error {
+	e.elements.reset()
+
+	if len(e.flags) == 0 {
+		return nil
+	}
+	vars, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	var merr MultiError
+	for _, ff := range e.flags {
+		v, ok := vars[ff.flag.Model().Envar]
+		if !ok || v.value == "" {
+			continue
+		}
+		values := []string{v.value}
+		if isCumulative(ff.flag.Model().Value) {
+			values = strings.Split(strings.TrimSuffix(strings.Replace(v.value, "\r\n", "\n", -1), "\n"), "\n")
+		}
+
+		if !e.isSetByFlagOrEnv(ctx, ff.flag) {
+			if err := ff.setValues(values); err != nil {
+				merr.Append(errors.Wrapf(err, "%s: environment variable %s", v.location, ff.flag.Model().Envar))
+			}
+		}
+		for _, s := range values {
+			e.elements.add(ctx, ff.flag, s, elementSource{source: SourceEnv, location: v.location})
+		}
+	}
+	return merr.Err()
+}
+
+// isSetByFlagOrEnv returns true if flag was set in command line, by real environment variable or other env file.
+func (e *envFile) isSetByFlagOrEnv(ctx *kingpin.ParseContext, f *kingpin.FlagClause) bool {
+	if f.HasEnvarValue() {
+		return true
+	}
+	for _, el := range ctx.Elements {
+		if el.Clause != f {
+			continue
+		}
+		if s, ok := e.sources.of(el); !ok || s.source == SourceEnv {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEnvFile parses dotenv file and adds its variables to vars.
+func parseEnvFile(b []byte, path string, vars map[string]envValue) error {
+	p := &envParser{b: bytes.Replace(b, []byte("\r\n"), []byte("\n"), -1), path: path, line: 1, vars: vars}
+	for {
+		p.skipSpaceAndComments()
+		if p.eof() {
+			return nil
+		}
+		if err := p.variable(); err != nil {
+			return err
+		}
+	}
+}
+
+type envParser struct {
+	b    []byte
+	pos  int
+	path string
+	line int
+	vars map[string]envValue
+}
+
+func (p *envParser) eof() bool { return p.pos >= len(p.b) }
+
+func (p *envParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("%s:%d: %s", p.path, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *envParser) next() byte {
+	c := p.b[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipSpaceAndComments skips white spaces, empty lines and comment lines.
+func (p *envParser) skipSpaceAndComments() {
+	for !p.eof() {
+		switch p.b[p.pos] {
+		case ' ', '\t', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *envParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *envParser) skipBlanks() {
+	for !p.eof() && (p.b[p.pos] == ' ' || p.b[p.pos] == '\t') {
+		p.next()
+	}
+}
+
+func isEnvNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (!first && (c >= '0' && c <= '9' || c == '.'))
+}
+
+func (p *envParser) name() string {
+	start := p.pos
+	for !p.eof() && isEnvNameChar(p.b[p.pos], p.pos == start) {
+		p.next()
+	}
+	return string(p.b[start:p.pos])
+}
+
+// variable parses single `[export ]KEY=value` entry.
+func (p *envParser) variable() error {
+	line := p.line
+	name := p.name()
+	if name == "export" && !p.eof() && (p.b[p.pos] == ' ' || p.b[p.pos] == '\t') {
+		p.skipBlanks()
+		name = p.name()
+	}
+	if name == "" {
+		return p.errorf("expected variable name")
+	}
+	p.skipBlanks()
+	if p.eof() || p.b[p.pos] != '=' {
+		return p.errorf("expected = after variable name %s", name)
+	}
+	p.next()
+	p.skipBlanks()
+
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case p.eof():
+	case p.b[p.pos] == '\'':
+		value, err = p.singleQuoted()
+	case p.b[p.pos] == '"':
+		value, err = p.doubleQuoted()
+	default:
+		value = p.unquoted()
+	}
+	if err != nil {
+		return err
+	}
+	if !p.eof() && p.b[p.pos] != '\n' {
+		// Only comment can follow the quoted value.
+		p.skipBlanks()
+		if !p.eof() && p.b[p.pos] != '\n' && p.b[p.pos] != '#' {
+			return p.errorf("unexpected characters after value of %s", name)
+		}
+		p.skipLine()
+	}
+	p.vars[name] = envValue{value: value, location: fmt.Sprintf("%s:%d", p.path, line)}
+	return nil
+}
+
+func (p *envParser) singleQuoted() (string, error) {
+	p.next()
+	start := p.pos
+	for !p.eof() {
+		if p.b[p.pos] == '\'' {
+			v := string(p.b[start:p.pos])
+			p.next()
+			return v, nil
+		}
+		p.next()
+	}
+	return "", p.errorf("unterminated single quoted value")
+}
+
+func (p *envParser) doubleQuoted() (string, error) {
+	p.next()
+	var buf bytes.Buffer
+	for !p.eof() {
+		c := p.next()
+		switch c {
+		case '"':
+			return buf.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated double quoted value")
+			}
+			switch e := p.next(); e {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case 'r':
+				buf.WriteByte('\r')
+			case '"', '\\', '$':
+				buf.WriteByte(e)
+			default:
+				buf.WriteByte('\\')
+				buf.WriteByte(e)
+			}
+		case '$':
+			v, err := p.interpolate()
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(v)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated double quoted value")
+}
+
+// unquoted parses value till the end of line or comment preceded by white space.
+func (p *envParser) unquoted() string {
+	var buf bytes.Buffer
+	for !p.eof() && p.b[p.pos] != '\n' {
+		c := p.b[p.pos]
+		if c == '#' && buf.Len() > 0 && (buf.Bytes()[buf.Len()-1] == ' ' || buf.Bytes()[buf.Len()-1] == '\t') {
+			break
+		}
+		p.next()
+		if c == '$' {
+			// Unterminated ${ is kept as it is in unquoted values.
+			start := p.pos
+			v, err := p.interpolate()
+			if err != nil {
+				p.pos = start
+				buf.WriteByte(c)
+				continue
+			}
+			buf.WriteString(v)
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return strings.TrimRight(buf.String(), " \t")
+}
+
+// interpolate parses variable reference after $ and returns its value. Real environment variables have priority
+// over variables defined earlier in the file.
+func (p *envParser) interpolate() (string, error) {
+	braces := !p.eof() && p.b[p.pos] == '{'
+	if braces {
+		p.next()
+	}
+	name := p.name()
+	if braces {
+		if p.eof() || p.b[p.pos] != '}' {
+			return "", p.errorf("unterminated ${ in value")
+		}
+		p.next()
+	}
+	if name == "" {
+		if braces {
+			return "", p.errorf("empty variable name in ${}")
+		}
+		return "$", nil
+	}
+	if v, ok := os.LookupEnv(name); ok {
+		return v, nil
+	}
+	return p.vars[name].value, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type envFileConfig struct {
+	Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|envvar=ENVFILE_TIMEOUT"`
+	Name    string        `flagarize:"name=name|help=Name.|required=true|envvar=ENVFILE_NAME"`
+	Peers   []string      `flagarize:"name=peer|help=Peers.|default=a|envvar=ENVFILE_PEERS"`
+	Retries int           `flagarize:"name=retries|help=Retries.|envvar=ENVFILE_RETRIES|max=10"`
+	Address string        `flagarize:"name=address|help=Address.|default=:80"`
+}
+
+func writeEnvFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "flagarize")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(dir)) })
+
+	p := filepath.Join(dir, name)
+	testutil.Ok(t, ioutil.WriteFile(p, []byte(content), os.ModePerm))
+	return p
+}
+
+func TestFlagarize_EnvFile(t *testing.T) {
+	t.Run("precedence", func(t *testing.T) {
+		testutil.Ok(t, os.Setenv("ENVFILE_TIMEOUT", "5m"))
+		defer func() { testutil.Ok(t, os.Unsetenv("ENVFILE_TIMEOUT")) }()
+
+		p := writeEnvFile(t, ".env", `# Comment.
+ENVFILE_TIMEOUT=10m
+export ENVFILE_NAME = from-file # Inline comment.
+ENVFILE_PEERS="b
+c"
+ENVFILE_RETRIES='3'
+`)
+		app := newTestKingpin(t)
+		cfg := &envFileConfig{}
+		f, err := flagarize.New(app, cfg, flagarize.WithEnvFile(p), flagarize.WithEnvFile(p+".missing"))
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"--address=:8080"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, &envFileConfig{
+			Timeout: 5 * time.Minute,
+			Name:    "from-file",
+			Peers:   []string{"b", "c"},
+			Retries: 3,
+			Address: ":8080",
+		}, cfg)
+
+		sources, err := f.Sources()
+		testutil.Ok(t, err)
+		testutil.Equals(t, flagarize.SourceEnv, sources["Timeout"].Source)
+		testutil.Equals(t, []string{"5m"}, sources["Timeout"].Raw)
+		testutil.Equals(t, flagarize.SourceEnv, sources["Name"].Source)
+		testutil.Equals(t, []string{"b", "c"}, sources["Peers"].Raw)
+	})
+	t.Run("flag overrides file; config file has lower priority", func(t *testing.T) {
+		p := writeEnvFile(t, ".env", "ENVFILE_NAME=from-env-file\nENVFILE_RETRIES=4\n")
+		c := writeConfigFile(t, `{"name": "from-config", "retries": 5, "timeout": "2m"}`)
+
+		app := newTestKingpin(t)
+		cfg := &envFileConfig{}
+		testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithEnvFileFlag(), flagarize.WithConfigFile()))
+
+		_, err := app.Parse([]string{"--env-file=" + p, "--config.file=" + c, "--retries=6"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, "from-env-file", cfg.Name)
+		testutil.Equals(t, 6, cfg.Retries)
+		testutil.Equals(t, 2*time.Minute, cfg.Timeout)
+	})
+	t.Run("interpolation and escapes", func(t *testing.T) {
+		testutil.Ok(t, os.Setenv("ENVFILE_TEST_HOST", "example.com"))
+		defer func() { testutil.Ok(t, os.Unsetenv("ENVFILE_TEST_HOST")) }()
+
+		p := writeEnvFile(t, ".env", `PREFIX=svc
+ENVFILE_NAME="${PREFIX}@$ENVFILE_TEST_HOST\t\$HOME"
+ENVFILE_PEERS='${PREFIX}'
+`)
+		app := newTestKingpin(t)
+		cfg := &envFileConfig{}
+		testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithEnvFile(p)))
+
+		_, err := app.Parse(nil)
+		testutil.Ok(t, err)
+		testutil.Equals(t, "svc@example.com\t$HOME", cfg.Name)
+		testutil.Equals(t, []string{"${PREFIX}"}, cfg.Peers)
+	})
+	t.Run("violation reports env file location", func(t *testing.T) {
+		p := writeEnvFile(t, ".env", "ENVFILE_NAME=a\n\nENVFILE_RETRIES=11\n")
+
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &envFileConfig{}, flagarize.WithEnvFile(p)))
+
+		_, err := app.Parse(nil)
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "invalid value \"11\" from environment variable ENVFILE_RETRIES from "+p+":3 (flag --retries): has to be less than or equal to 10", err.Error())
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		p := writeEnvFile(t, ".env", "ENVFILE_NAME=a\nENVFILE_RETRIES=\"11\n")
+
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &envFileConfig{}, flagarize.WithEnvFile(p)))
+
+		_, err := app.Parse(nil)
+		testutil.NotOk(t, err)
+		testutil.Equals(t, p+":3: unterminated double quoted value", err.Error())
+	})
+	t.Run("missing file given by flag", func(t *testing.T) {
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &envFileConfig{}, flagarize.WithEnvFileFlag()))
+
+		_, err := app.Parse([]string{"--env-file=/not/existing/.env", "--name=a"})
+		testutil.NotOk(t, err)
+	})
+}
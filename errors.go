@@ -0,0 +1,189 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// MultiError is a slice of errors implementing the error interface. It is returned by Flagarize with all registration
+// errors, as well as with all errors of checks after parse.
+type MultiError []error
+
+func (errs MultiError) Error() string {
+	switch len(errs) {
+	case 0:
+		return ""
+	case 1:
+		return errs[0].Error()
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%d error(s) occurred:", len(errs))
+	for _, err := range errs {
+		fmt.Fprintf(buf, "\n* %s", err)
+	}
+	return buf.String()
+}
+
+// Append appends the provided error if it is not nil. Errors of appended MultiError are appended one by one.
+func (errs *MultiError) Append(err error) {
+	switch e := err.(type) {
+	case nil:
+	case MultiError:
+		*errs = append(*errs, e...)
+	default:
+		*errs = append(*errs, err)
+	}
+}
+
+// Err returns nil if there are no errors or MultiError otherwise.
+func (errs MultiError) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// FieldPos is a position of the field in the flagarized struct.
+type FieldPos struct {
+	// Path is full path of the field, starting with the name of the flagarized struct type e.g "Config.Web.TLS.F".
+	Path FieldPath
+	// Location is a source location of the field: struct type with its package path and field name
+	// e.g "github.com/org/app/config.TLSConfig.F".
+	Location string
+}
+
+func newFieldPos(root string, path FieldPath, structType reflect.Type, field string) FieldPos {
+	p := FieldPos{Path: append(FieldPath{root}, path...), Location: structType.String() + "." + field}
+	if structType.Name() != "" {
+		p.Location = structType.PkgPath() + "." + structType.Name() + "." + field
+	}
+	return p
+}
+
+func (p FieldPos) String() string { return p.Path.String() }
+
+func (p *FieldPos) setPos(pos FieldPos) { *p = pos }
+
+// UnsupportedTypeError is returned if flagarized field has type that cannot be registered as flag.
+type UnsupportedTypeError struct {
+	FieldPos
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	t := "<nil>"
+	if e.Type != nil {
+		t = e.Type.String()
+	}
+	return fmt.Sprintf("%s: flagarize struct Tag found on not supported type %s", e.FieldPos, t)
+}
+
+// DuplicateFlagError is returned if flag with the same name was already registered.
+type DuplicateFlagError struct {
+	FieldPos
+	Flag string
+}
+
+func (e *DuplicateFlagError) Error() string {
+	return fmt.Sprintf("%s: flag --%s was already registered", e.FieldPos, e.Flag)
+}
+
+// PrivateFieldError is returned if flagarize struct tag is found on private field.
+type PrivateFieldError struct {
+	FieldPos
+}
+
+func (e *PrivateFieldError) Error() string {
+	return fmt.Sprintf("%s: flagarize struct Tag found on private field; it has to be exported", e.FieldPos)
+}
+
+// TagError is returned if flagarize struct tag is malformed.
+type TagError struct {
+	FieldPos
+	// Key is the struct tag key that is wrong, if known.
+	Key string
+	// Suggestion is the supported key similar to the not supported key, if any.
+	Suggestion string
+	Reason     string
+}
+
+func (e *TagError) Error() string {
+	msg := e.Reason
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf("; did you mean %q?", e.Suggestion)
+	}
+	if len(e.Path) == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", e.FieldPos, msg)
+}
+
+// FieldError is returned for all other errors of the flagarized field e.g errors of custom Flagarizers.
+type FieldError struct {
+	FieldPos
+	Err error
+}
+
+func (e *FieldError) Error() string { return fmt.Sprintf("%s: %s", e.FieldPos, e.Err) }
+
+// Cause returns underlying error.
+func (e *FieldError) Cause() error { return e.Err }
+
+// Unwrap returns underlying error.
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// withFieldPos returns error with the given field position. Errors not carrying position are wrapped in FieldError.
+func withFieldPos(err error, pos FieldPos) error {
+	if e, ok := err.(interface{ setPos(FieldPos) }); ok {
+		e.setPos(pos)
+		return err
+	}
+	return &FieldError{FieldPos: pos, Err: err}
+}
+
+// suggestKey returns the key from keys that is the most similar to the given one or empty string if none is similar.
+func suggestKey(key string, keys ...[]string) string {
+	best, bestDist := "", len(key)/2+1
+	for _, ks := range keys {
+		for _, k := range ks {
+			if d := levenshtein(key, k); d < bestDist {
+				best, bestDist = k, d
+			}
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
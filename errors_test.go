@@ -0,0 +1,65 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+	"github.com/pkg/errors"
+)
+
+type errTLSConfig struct {
+	Cert string         `flagarize:"name=cert|help=Cert.|envar=CERT"`
+	Key  map[string]int `flagarize:"name=key|help=Key."`
+	ca   string         `flagarize:"name=ca|help=CA."`
+}
+
+type errConfig struct {
+	TLS   errTLSConfig `flagarize:"prefx=tls."`
+	Web   errTLSConfig `flagarize:"prefix=web."`
+	Name  string       `flagarize:"name=name|help=Name."`
+	Host  string       `flagarize:"name=host|help=Host.|prefx=h."`
+	Debug bool         `flagarize:"name=name|help=Debug."`
+}
+
+func TestFlagarize_AggregatedErrors(t *testing.T) {
+	err := flagarize.Flagarize(newTestKingpin(t), &errConfig{})
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "flagarize: 6 error(s) occurred:\n"+
+		"* errConfig.TLS: expected map-like Tag elements (e.g prefix=web.) separated with |, found but no supported key found \"prefx\" for field \"TLS\"; only [prefix envprefix group hidden required cmd] are supported for nested structs and [name help hidden required default envvar short placeholder group requires conflicts oneof min max minlen maxlen pattern nonempty enum secret arg key deprecated] for flags; did you mean \"prefix\"?\n"+
+		"* errConfig.Web.Cert: expected map-like Tag elements (e.g hidden=true) separated with |, found but no supported key found \"envar\" for field \"Cert\"; only [name help hidden required default envvar short placeholder group requires conflicts oneof min max minlen maxlen pattern nonempty enum secret arg key deprecated] are supported; did you mean \"envvar\"?\n"+
+		"* errConfig.Web.Key: flagarize struct Tag found on not supported type map[string]int\n"+
+		"* errConfig.Web.ca: flagarize struct Tag found on private field; it has to be exported\n"+
+		"* errConfig.Host: expected map-like Tag elements (e.g hidden=true) separated with |, found but no supported key found \"prefx\" for field \"Host\"; only [name help hidden required default envvar short placeholder group requires conflicts oneof min max minlen maxlen pattern nonempty enum secret arg key deprecated] are supported\n"+
+		"* errConfig.Debug: flag --name was already registered", err.Error())
+
+	merr, ok := errors.Cause(err).(flagarize.MultiError)
+	testutil.Assert(t, ok, "expected MultiError, got %T", errors.Cause(err))
+
+	var types []string
+	for _, e := range merr {
+		types = append(types, reflect.TypeOf(e).String())
+	}
+	testutil.Equals(t, []string{
+		"*flagarize.TagError",
+		"*flagarize.TagError",
+		"*flagarize.UnsupportedTypeError",
+		"*flagarize.PrivateFieldError",
+		"*flagarize.TagError",
+		"*flagarize.DuplicateFlagError",
+	}, types)
+	testutil.Equals(t, "", merr[4].(*flagarize.TagError).Suggestion)
+
+	tagErr := merr[1].(*flagarize.TagError)
+	testutil.Equals(t, flagarize.FieldPath{"errConfig", "Web", "Cert"}, tagErr.Path)
+	testutil.Equals(t, "github.com/bwplotka/flagarize_test.errTLSConfig.Cert", tagErr.Location)
+	testutil.Equals(t, "envar", tagErr.Key)
+	testutil.Equals(t, "envvar", tagErr.Suggestion)
+
+	dupErr := merr[5].(*flagarize.DuplicateFlagError)
+	testutil.Equals(t, "name", dupErr.Flag)
+}
@@ -4,6 +4,7 @@
 package flagarize
 
 import (
+	"fmt"
 	"net"
 	"net/url"
 	"os"
@@ -30,14 +31,20 @@ const (
 	envvarStructTagKey      = "envvar"
 	shortStructTagKey       = "short"
 	placeholderStructTagKey = "placeholder"
+	groupStructTagKey       = "group"
+	deprecatedStructTagKey  = "deprecated"
 )
 
-var supportedStuctTagKeys = []string{nameStructTagKey, helpStructTagKey, hiddenStructTagKey, requiredStructTagKey, defaultStructTagKey, envvarStructTagKey, shortStructTagKey, placeholderStructTagKey}
+var supportedStuctTagKeys = []string{nameStructTagKey, helpStructTagKey, hiddenStructTagKey, requiredStructTagKey, defaultStructTagKey, envvarStructTagKey, shortStructTagKey, placeholderStructTagKey, groupStructTagKey, requiresStructTagKey, conflictsStructTagKey, oneofStructTagKey,
+	minStructTagKey, maxStructTagKey, minlenStructTagKey, maxlenStructTagKey, patternStructTagKey, nonemptyStructTagKey, enumStructTagKey,
+	secretStructTagKey, argStructTagKey, keyStructTagKey, deprecatedStructTagKey}
 
 // ValueFlagarizer is the simplest way to extend flagarize to parse your custom type.
 // If any field has `flagarize:` struct tag and it implements the ValueFlagarizer, this will be
 // used by kingping to parse the flag value.
 //
+// For types without Set method see Value option.
+//
 // For an example see: `./timeduration.go` or `./regexp.go`.
 type ValueFlagarizer interface {
 	// FlagarizeSetValue is invoked on kinpgin.Parse with the flag value passed as string.
@@ -78,8 +85,50 @@ type KingpinRegistry interface {
 	GetFlag(name string) *kingpin.FlagClause
 }
 
+// Registry allows registering a flag, a positional argument and a command. It is used by code generated with
+// Generate. Example implementations are *kingpin.Application and *kingpin.CmdClause.
+type Registry interface {
+	FlagRegisterer
+	Arg(name, help string) *kingpin.ArgClause
+	Command(name, help string) *kingpin.CmdClause
+}
+
 type opts struct {
-	elemSep string
+	elemSep          string
+	helpResolver     HelpResolver
+	valuesAsDefaults bool
+	groupedHelp      bool
+	collapsedGroups  bool
+	validate         bool
+	omitDefaults     bool
+	strict           bool
+	withConfigFile   bool
+	envFiles         []string
+	envFileFlag      bool
+	sources          []Source
+	values           map[reflect.Type]customValue
+
+	// root is the name of the flagarized struct type.
+	root        string
+	helpGroups  *helpGroups
+	constraints *constraints
+	configFile  *configFile
+	envFile     *envFile
+	lookup      *lookupSources
+	// elementSources are parse elements added by envFile, lookup and configFile.
+	elementSources elementSources
+	// sourceFields are fields which value sources are tracked.
+	sourceFields *[]sourceField
+	// specs are specs of registered flags collected by Describe. Sources are not tracked if not nil.
+	specs *[]FlagSpec
+	// cmd is the command flags are registered in, nil for top level flags.
+	cmd *kingpin.CmdClause
+	// parents are the application and commands cmd is nested in, so constraints can reference their flags.
+	parents  []KingpinRegistry
+	commands *[]*command
+	// commandSelected reports if command under given path was selected on parse. If not nil, fields of commands
+	// that were not selected are not walked.
+	commandSelected func(FieldPath) bool
 }
 
 func (o opts) apply(optFuncs ...OptFunc) opts {
@@ -95,6 +144,64 @@ type OptFunc func(opt *opts)
 // WithElemSep sets custom divider for elements in flagarize struct tag. It is "|" by default.
 func WithElemSep(val string) OptFunc { return func(opt *opts) { opt.elemSep = val } }
 
+// WithHelpResolver sets resolver that is asked for help of every flag that does not have help=<help> in struct Tag,
+// nor help from struct HelpProvider or `<Field>FlagarizeHelp` field. This allows help to come from
+// any central place like catalogue, generated docs or translated strings.
+func WithHelpResolver(resolver HelpResolver) OptFunc {
+	return func(opt *opts) { opt.helpResolver = resolver }
+}
+
+// WithValuesAsDefaults makes non-zero values of fields present at the Flagarize invocation the defaults of their
+// flags. Such default is rendered in the canonical format of the type (see ValueFormatter) and shown in help.
+// This allows to flagarize config with programmatic defaults e.g. `cfg := DefaultConfig()`.
+//
+// Non-zero field value has priority over `default=` struct tag. Zero values (including empty slices and maps) are
+// ignored, so `default=` tag (if any) is used. Values of required flags and fields with custom Flagarizer are ignored.
+func WithValuesAsDefaults() OptFunc { return func(opt *opts) { opt.valuesAsDefaults = true } }
+
+// WithGroupedHelp makes --help render flags under headings of their groups (see `group` struct tag key) in
+// declaration order. Flags without group are rendered first. It also registers --help-group=<group> flag that
+// shows help only for flags of the given group.
+// It requires KingpinRegistry to be *kingpin.Application. NOTE: It overrides usage template of the application.
+func WithGroupedHelp() OptFunc { return func(opt *opts) { opt.groupedHelp = true } }
+
+// WithCollapsedGroupedHelp is like WithGroupedHelp, but groups are collapsed in --help: only group names are rendered
+// instead of their flags. Use --help-group=<group> to show flags of the group.
+func WithCollapsedGroupedHelp() OptFunc {
+	return func(opt *opts) {
+		opt.groupedHelp = true
+		opt.collapsedGroups = true
+	}
+}
+
+// WithStrict makes Flagarize fail if any exported field is silently not flagarized: leaf fields without flagarize
+// struct tag and tagged fields of types registered by custom Flagarizer (their tags are ignored). Fields can be
+// excluded explicitly with `flagarize:"-"` struct tag. See Coverage for the report without failing.
+func WithStrict() OptFunc { return func(opt *opts) { opt.strict = true } }
+
+// HelpResolver returns help for the flag registered from the field under given path. Empty string means no help.
+type HelpResolver func(path FieldPath, tag *Tag) string
+
+// HelpProvider can be implemented by any flagarized struct to provide help for its fields, without
+// the need for `<Field>FlagarizeHelp` fields.
+type HelpProvider interface {
+	// FlagarizeHelp returns help for the given field name of the struct. Empty string means no help.
+	FlagarizeHelp(field string) string
+}
+
+// FieldPath is a path of struct field names leading from the flagarized struct to the field.
+// For example field F in the struct under Web.TLS field has FieldPath{"Web", "TLS", "F"}.
+type FieldPath []string
+
+// String returns path in the dot notation e.g "Web.TLS.F".
+func (p FieldPath) String() string { return strings.Join(p, ".") }
+
+// Child returns new path with given field name appended.
+func (p FieldPath) Child(name string) FieldPath {
+	c := make(FieldPath, 0, len(p)+1)
+	return append(append(c, p...), name)
+}
+
 // Flagarize registers flags based on `flagarize:"..."` struct tags.
 //
 // If field is a type that implemented Flagarizer or ValueFlagaizer interface, the custom Flagarizer will be used
@@ -144,179 +251,385 @@ func WithElemSep(val string) OptFunc { return func(opt *opts) { opt.elemSep = va
 //		// Config is filled with flags from value!
 //		_ = cfg.Field1
 //	}.
-// Error is returned if the struct cannot be registered as flags.
+// Error is returned if the struct cannot be registered as flags. Errors of all fields are returned in MultiError (see
+// errors.Cause), each being one of *TagError, *UnsupportedTypeError, *DuplicateFlagError, *PrivateFieldError or
+// *FieldError with the full path and location of the field.
 func Flagarize(r KingpinRegistry, s interface{}, o ...OptFunc) error {
+	_, err := New(r, s, o...)
+	return err
+}
+
+// Flagarized is a handle of the struct flagarized with New. It reports what happened on the last parse of the
+// registry the struct was flagarized in.
+type Flagarized struct {
+	s        interface{}
+	sources  sourceTracker
+	commands commandTracker
+}
+
+// New registers flags based on `flagarize:"..."` struct tags the same as Flagarize does and returns handle of the
+// flagarized struct.
+func New(r KingpinRegistry, s interface{}, o ...OptFunc) (*Flagarized, error) {
 	if r == nil {
-		return errors.New("flagarize: FlagRegisterer cannot be nil")
+		return nil, errors.New("flagarize: FlagRegisterer cannot be nil")
 	}
 	if s == nil {
-		return errors.New("flagarize: object cannot be nil")
+		return nil, errors.New("flagarize: object cannot be nil")
 	}
 	v := reflect.ValueOf(s)
 	if v.Kind() != reflect.Ptr {
-		return errors.New("flagarize: object must be a pointer to struct or interface")
+		return nil, errors.New("flagarize: object must be a pointer to struct or interface")
 	}
 	if v.IsNil() {
-		return errors.New("flagarize: object cannot be nil")
+		return nil, errors.New("flagarize: object cannot be nil")
 	}
 	switch e := v.Elem(); e.Kind() {
 	case reflect.Struct:
-		if err := parseStruct(r, e, opts{
+		opt := opts{
 			elemSep: "|",
-		}.apply(o...)); err != nil {
-			return errors.Wrap(err, "flagarize")
+		}.apply(o...)
+		opt.root = e.Type().Name()
+		if opt.root == "" {
+			opt.root = e.Type().String()
 		}
-		return nil
+		opt.constraints = newConstraints()
+		opt.sourceFields = &[]sourceField{}
+		opt.commands = &[]*command{}
+		if opt.groupedHelp {
+			h, err := installHelpGroups(r, opt.collapsedGroups)
+			if err != nil {
+				return nil, errors.Wrap(err, "flagarize")
+			}
+			opt.helpGroups = h
+		}
+		if len(opt.envFiles) > 0 || opt.envFileFlag {
+			ef, err := installEnvFile(r, opt.envFileFlag)
+			if err != nil {
+				return nil, errors.Wrap(err, "flagarize")
+			}
+			ef.paths = append(ef.paths, opt.envFiles...)
+			opt.envFile = ef
+		}
+		if len(opt.sources) > 0 {
+			l, err := installSources(r, opt.sources)
+			if err != nil {
+				return nil, errors.Wrap(err, "flagarize")
+			}
+			opt.lookup = l
+		}
+		if opt.withConfigFile {
+			c, err := installConfigFile(r)
+			if err != nil {
+				return nil, errors.Wrap(err, "flagarize")
+			}
+			opt.configFile = c
+		}
+		opt.elementSources = opt.installedElementSources()
+		if opt.envFile != nil {
+			opt.envFile.sources = append(opt.envFile.sources, opt.elementSources...)
+		}
+		var merr MultiError
+		merr.Append(parseStruct(r, e, nil, &nestedTag{}, opt))
+		if opt.strict {
+			merr.Append(coverage(e.Type(), opt).Err())
+		}
+		if err := merr.Err(); err != nil {
+			return nil, errors.Wrap(err, "flagarize")
+		}
+		f := &Flagarized{s: s}
+		if opt.specs == nil {
+			f.sources.install(r, *opt.sourceFields, opt.elementSources)
+			if err := f.commands.install(r, *opt.commands); err != nil {
+				return nil, errors.Wrap(err, "flagarize")
+			}
+		}
+		if err := opt.constraints.install(r, nil); err != nil {
+			return nil, errors.Wrap(err, "flagarize")
+		}
+		if opt.validate {
+			commands := *opt.commands
+			if err := addPostParseAction(r, func(ctx *kingpin.ParseContext) error {
+				o := opt
+				o.commandSelected = selectedCommands(ctx, commands)
+				return validate(e, o)
+			}); err != nil {
+				return nil, errors.Wrap(err, "flagarize")
+			}
+		}
+		return f, nil
 	default:
-		return errors.Errorf("flagarize: object must be a pointer to struct or interface, got: %s", e)
+		return nil, errors.Errorf("flagarize: object must be a pointer to struct or interface, got: %s", e)
 	}
 }
 
 type dedupFlagRegisterer struct {
 	KingpinRegistry
 	duplicate string
+	clauses   []*kingpin.FlagClause
 }
 
 func (d *dedupFlagRegisterer) Flag(name, help string) *kingpin.FlagClause {
 	if d.GetFlag(name) != nil {
 		d.duplicate = name
 	}
-	return d.KingpinRegistry.Flag(name, help)
+	c := d.KingpinRegistry.Flag(name, help)
+	d.clauses = append(d.clauses, c)
+	return c
 }
 
-func parseStruct(r KingpinRegistry, value reflect.Value, o opts) error {
+func parseStruct(r KingpinRegistry, value reflect.Value, path FieldPath, parent *nestedTag, o opts) error {
+	var merr MultiError
 	helpVars := parseHelpVars(value)
+	helpProvider := structHelpProvider(value)
 	for i := 0; i < value.NumField(); i++ {
 		field := value.Type().Field(i)
 		fieldValue := value.Field(i)
+		fieldPath := path.Child(field.Name)
+		pos := newFieldPos(o.root, fieldPath, value.Type(), field.Name)
+		if isIgnored(field) {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct && !implementsFlagarizer(field.Type) && !implementsValueFlagarizer(field.Type) && o.customValue(field.Type) == nil {
+			nested, ok, err := parseNestedTag(field, parent, fieldPath, o.elemSep)
+			if err != nil {
+				merr.Append(withFieldPos(err, pos))
+				continue
+			}
+			if ok {
+				if nested != parent && field.PkgPath != "" && !field.Anonymous {
+					merr.Append(&PrivateFieldError{FieldPos: pos})
+					continue
+				}
+				if nested.cmd != "" {
+					merr.Append(parseCommand(r, fieldValue, fieldPath, pos, nested, o))
+					continue
+				}
+				if field.PkgPath == "" || field.Anonymous {
+					merr.Append(parseStruct(r, fieldValue, fieldPath, nested, o))
+				}
+				if rt := nested.ownRequiredTogether; rt != nil && len(rt.flags) > 0 {
+					o.constraints.requiredTogether = append(o.constraints.requiredTogether, rt)
+				}
+				continue
+			}
+		}
 
-		tag, err := parseTag(field, helpVars[field.Name], o.elemSep)
+		tag, err := parseTag(field, parent, func(t *Tag) *string {
+			if helpProvider != nil {
+				if h := helpProvider.FlagarizeHelp(field.Name); h != "" {
+					return &h
+				}
+			}
+			if h, ok := helpVars[field.Name]; ok {
+				return h
+			}
+			if o.helpResolver != nil {
+				if h := o.helpResolver(fieldPath, t); h != "" {
+					return &h
+				}
+			}
+			return nil
+		}, o.elemSep)
 		if err != nil {
-			return errors.Wrap(err, "parse flagarize tags")
+			merr.Append(withFieldPos(err, pos))
+			continue
 		}
 
 		if tag == nil {
 			if fieldValue.Kind() == reflect.Struct && (field.PkgPath == "" || field.Anonymous) {
-				if err := parseStruct(r, fieldValue, o); err != nil {
-					return err
-				}
+				merr.Append(parseStruct(r, fieldValue, fieldPath, parent, o))
 			}
 			continue
 		}
+		merr.Append(parseField(r, field, fieldValue, fieldPath, pos, tag, o))
+	}
+	return merr.Err()
+}
 
-		if field.PkgPath != "" {
-			return errors.Errorf("flagarize struct Tag found on private field %q; it has to be exported", field.Name)
-		}
+// parseField registers flag for the field with flagarize struct tag.
+func parseField(r KingpinRegistry, field reflect.StructField, fieldValue reflect.Value, fieldPath FieldPath, pos FieldPos, tag *Tag, o opts) error {
+	if field.PkgPath != "" {
+		return &PrivateFieldError{FieldPos: pos}
+	}
 
-		if r.GetFlag(tag.Name) != nil {
-			return errors.Errorf("flagarize field %s was already registered", field.Name)
-		}
+	if !tag.Arg && r.GetFlag(tag.Name) != nil {
+		return &DuplicateFlagError{FieldPos: pos, Flag: tag.Name}
+	}
 
-		if !fieldValue.CanAddr() {
-			return errors.Errorf("flagarize struct Tag found on non-addressable field %q", field.Name)
-		}
+	if !fieldValue.CanAddr() {
+		return withFieldPos(errors.New("flagarize struct Tag found on non-addressable field"), pos)
+	}
 
-		// Favor custom Flagarizers if specified.
-		d := &dedupFlagRegisterer{KingpinRegistry: r}
-		ok, err := invokeFlagarizersIfImplements(d, tag, fieldValue, field.Name)
-		if err != nil {
-			return err
-		}
-		if ok {
-			if d.duplicate != "" {
-				return errors.Errorf("flagarize field %s was already registered", d.duplicate)
+	customFlagarizer := implementsFlagarizer(fieldValue.Type()) && o.customValue(fieldValue.Type()) == nil
+	if o.valuesAsDefaults && !tag.Required && !customFlagarizer {
+		if defs := o.formatValue(fieldValue); len(defs) > 0 {
+			tag.DefaultValue = defs[0]
+			tag.defaultValues = defs
+			if k := fieldValue.Kind(); k == reflect.Slice || k == reflect.Map {
+				// Kingpin appends to repeatable values, so start from scratch.
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
 			}
-			continue
 		}
+	}
+
+	if tag.Deprecated != "" {
+		tag.Help = strings.TrimSpace(fmt.Sprintf("%s (deprecated: %s)", tag.Help, tag.Deprecated))
+	}
+	if tag.Arg {
+		return parseArg(r, fieldValue, fieldPath, pos, tag, o)
+	}
+
+	vc, err := newValueCheck(tag, fieldValue.Type())
+	if err != nil {
+		return withFieldPos(err, pos)
+	}
+	if vc != nil {
+		tag.Help = strings.TrimSpace(tag.Help + " " + vc.help)
+	}
+
+	// Favor custom Flagarizers if specified.
+	d := &dedupFlagRegisterer{KingpinRegistry: r}
+	ok, err := registerCustomValue(d, tag, fieldValue, o)
+	if err != nil {
+		return withFieldPos(err, pos)
+	}
+	if ok && d.duplicate != "" {
+		return &DuplicateFlagError{FieldPos: pos, Flag: d.duplicate}
+	}
 
-		clause := tag.Flag(r)
-		switch fieldValue.Interface().(type) {
-		// TODO(bwplotka): Support Enums and maybe hex?
-		case string:
-			clause.StringVar((*string)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case bool:
-			clause.BoolVar((*bool)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case uint:
-			clause.UintVar((*uint)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case uint8:
-			clause.Uint8Var((*uint8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case uint16:
-			clause.Uint16Var((*uint16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case uint32:
-			clause.Uint32Var((*uint32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case uint64:
-			clause.Uint64Var((*uint64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case int:
-			clause.IntVar((*int)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case int8:
-			clause.Int8Var((*int8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case int16:
-			clause.Int16Var((*int16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case int32:
-			clause.Int32Var((*int32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case int64:
-			clause.Int64Var((*int64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case float32:
-			clause.Float32Var((*float32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case float64:
-			clause.Float64Var((*float64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case time.Duration:
-			clause.DurationVar((*time.Duration)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case net.IP:
-			clause.IPVar((*net.IP)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case units.Base2Bytes:
-			clause.BytesVar((*units.Base2Bytes)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case *net.TCPAddr:
-			clause.TCPVar((**net.TCPAddr)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case *url.URL:
-			clause.URLVar((**url.URL)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case *os.File:
-			clause.FileVar((**os.File)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []bool:
-			clause.BoolListVar((*[]bool)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []string:
-			clause.StringsVar((*[]string)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []int:
-			clause.IntsVar((*[]int)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []int8:
-			clause.Int8ListVar((*[]int8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []int16:
-			clause.Int16ListVar((*[]int16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []int32:
-			clause.Int32ListVar((*[]int32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []int64:
-			clause.Int64ListVar((*[]int64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []uint:
-			clause.UintsVar((*[]uint)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []uint8:
-			clause.Uint8ListVar((*[]uint8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []uint16:
-			clause.Uint16ListVar((*[]uint16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []uint32:
-			clause.Uint32ListVar((*[]uint32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []uint64:
-			clause.Uint64ListVar((*[]uint64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []float32:
-			clause.Float32ListVar((*[]float32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []float64:
-			clause.Float64ListVar((*[]float64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []time.Duration:
-			clause.DurationListVar((*[]time.Duration)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []net.IP:
-			clause.IPListVar((*[]net.IP)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []*net.TCPAddr:
-			clause.TCPListVar((*[]*net.TCPAddr)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case []*url.URL:
-			clause.URLListVar((*[]*url.URL)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		case map[string]string:
-			if fieldValue.IsNil() {
-				fieldValue.Set(reflect.MakeMap(fieldValue.Type()))
+	if !ok {
+		if err := registerBuiltinValue(tag.Flag(d), fieldValue); err != nil {
+			return withFieldPos(err, pos)
+		}
+	}
+	if !customFlagarizer {
+		tag.addToRequiredTogether(d.clauses...)
+	}
+	if err := o.constraints.add(tag, d.clauses...); err != nil {
+		return withFieldPos(err, pos)
+	}
+	if vc != nil {
+		if len(d.clauses) != 1 {
+			return withFieldPos(errors.New("value constraints are supported only for fields registering single flag"), pos)
+		}
+		vc.flag, vc.value, vc.sources = d.clauses[0], fieldValue, o.elementSources
+		o.constraints.values = append(o.constraints.values, vc)
+	}
+	o.helpGroups.add(tag.Group, d.clauses...)
+	if err := o.configFile.add(tag, fieldValue, d.clauses...); err != nil {
+		return withFieldPos(err, pos)
+	}
+	o.envFile.add(fieldValue, d.clauses...)
+	if o.specs != nil || o.lookup != nil {
+		for _, c := range d.clauses {
+			spec := newFlagSpec(c, tag, fieldPath, fieldValue.Type())
+			if o.cmd != nil {
+				spec.Command = o.cmd.FullCommand()
+			}
+			if o.specs != nil {
+				*o.specs = append(*o.specs, spec)
 			}
-			clause.StringMapVar((*map[string]string)(unsafe.Pointer(fieldValue.Addr().Pointer())))
-		default:
-			return errors.Errorf("flagarize struct Tag found on not supported type %s %T for field %q", fieldValue.Kind().String(), fieldValue.Interface(), field.Name)
+			v := fieldValue
+			if len(d.clauses) != 1 {
+				v = reflect.Value{}
+			}
+			o.lookup.add(spec, c, v, o.cmd)
+		}
+	}
+	if len(d.clauses) > 0 {
+		*o.sourceFields = append(*o.sourceFields, sourceField{path: fieldPath, flags: d.clauses})
+	}
+	return nil
+}
 
+// registerBuiltinValue registers field value of the natively supported type as the flag value.
+func registerBuiltinValue(clause *kingpin.FlagClause, fieldValue reflect.Value) error {
+	switch fieldValue.Interface().(type) {
+	// TODO(bwplotka): Support Enums and maybe hex?
+	case string:
+		clause.StringVar((*string)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case bool:
+		clause.BoolVar((*bool)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case uint:
+		clause.UintVar((*uint)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case uint8:
+		clause.Uint8Var((*uint8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case uint16:
+		clause.Uint16Var((*uint16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case uint32:
+		clause.Uint32Var((*uint32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case uint64:
+		clause.Uint64Var((*uint64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case int:
+		clause.IntVar((*int)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case int8:
+		clause.Int8Var((*int8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case int16:
+		clause.Int16Var((*int16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case int32:
+		clause.Int32Var((*int32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case int64:
+		clause.Int64Var((*int64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case float32:
+		clause.Float32Var((*float32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case float64:
+		clause.Float64Var((*float64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case time.Duration:
+		clause.DurationVar((*time.Duration)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case net.IP:
+		clause.IPVar((*net.IP)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case units.Base2Bytes:
+		clause.BytesVar((*units.Base2Bytes)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case *net.TCPAddr:
+		clause.TCPVar((**net.TCPAddr)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case *url.URL:
+		clause.URLVar((**url.URL)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case *os.File:
+		clause.FileVar((**os.File)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []bool:
+		clause.BoolListVar((*[]bool)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []string:
+		clause.StringsVar((*[]string)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []int:
+		clause.IntsVar((*[]int)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []int8:
+		clause.Int8ListVar((*[]int8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []int16:
+		clause.Int16ListVar((*[]int16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []int32:
+		clause.Int32ListVar((*[]int32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []int64:
+		clause.Int64ListVar((*[]int64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []uint:
+		clause.UintsVar((*[]uint)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []uint8:
+		clause.Uint8ListVar((*[]uint8)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []uint16:
+		clause.Uint16ListVar((*[]uint16)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []uint32:
+		clause.Uint32ListVar((*[]uint32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []uint64:
+		clause.Uint64ListVar((*[]uint64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []float32:
+		clause.Float32ListVar((*[]float32)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []float64:
+		clause.Float64ListVar((*[]float64)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []time.Duration:
+		clause.DurationListVar((*[]time.Duration)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []net.IP:
+		clause.IPListVar((*[]net.IP)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []*net.TCPAddr:
+		clause.TCPListVar((*[]*net.TCPAddr)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case []*url.URL:
+		clause.URLListVar((*[]*url.URL)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	case map[string]string:
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.MakeMap(fieldValue.Type()))
 		}
+		clause.StringMapVar((*map[string]string)(unsafe.Pointer(fieldValue.Addr().Pointer())))
+	default:
+		return &UnsupportedTypeError{Type: fieldValue.Type()}
 	}
 	return nil
 }
@@ -329,11 +642,24 @@ func allocPtrIfNil(fieldValue reflect.Value) {
 	}
 }
 
-func invokeFlagarizersIfImplements(r KingpinRegistry, tag *Tag, fieldValue reflect.Value, name string) (impl bool, err error) {
+var (
+	flagarizerType      = reflect.TypeOf((*Flagarizer)(nil)).Elem()
+	valueFlagarizerType = reflect.TypeOf((*ValueFlagarizer)(nil)).Elem()
+)
+
+func implementsFlagarizer(t reflect.Type) bool {
+	return t.Implements(flagarizerType) || reflect.PtrTo(t).Implements(flagarizerType)
+}
+
+func implementsValueFlagarizer(t reflect.Type) bool {
+	return t.Implements(valueFlagarizerType) || reflect.PtrTo(t).Implements(valueFlagarizerType)
+}
+
+func invokeFlagarizersIfImplements(r KingpinRegistry, tag *Tag, fieldValue reflect.Value) (impl bool, err error) {
 	if _, ok := fieldValue.Interface().(Flagarizer); ok {
 		allocPtrIfNil(fieldValue)
 		// Do fieldValue.Interface() once more as after alloc the copied value is not changed.
-		if err := invokeCustomFlagarizer(r, fieldValue.Interface().(Flagarizer), tag, fieldValue, name); err != nil {
+		if err := invokeCustomFlagarizer(r, fieldValue.Interface().(Flagarizer), tag, fieldValue); err != nil {
 			return true, err
 		}
 		return true, nil
@@ -342,7 +668,7 @@ func invokeFlagarizersIfImplements(r KingpinRegistry, tag *Tag, fieldValue refle
 	if _, ok := fieldValue.Addr().Interface().(Flagarizer); ok {
 		allocPtrIfNil(fieldValue)
 		// Do fieldValue.Interface() once more as after alloc the copied value is not changed.
-		if err := invokeCustomFlagarizer(r, fieldValue.Addr().Interface().(Flagarizer), tag, fieldValue, name); err != nil {
+		if err := invokeCustomFlagarizer(r, fieldValue.Addr().Interface().(Flagarizer), tag, fieldValue); err != nil {
 			return true, err
 		}
 		return true, nil
@@ -351,7 +677,7 @@ func invokeFlagarizersIfImplements(r KingpinRegistry, tag *Tag, fieldValue refle
 	if _, ok := fieldValue.Interface().(ValueFlagarizer); ok {
 		allocPtrIfNil(fieldValue)
 		// Do fieldValue.Interface() once more as after alloc the copied value is not changed.
-		if err := invokeCustomValueFlagarizer(r, fieldValue.Interface().(ValueFlagarizer), tag, fieldValue, name); err != nil {
+		if err := invokeCustomValueFlagarizer(r, fieldValue.Interface().(ValueFlagarizer), tag, fieldValue); err != nil {
 			return true, err
 		}
 		return true, nil
@@ -360,7 +686,7 @@ func invokeFlagarizersIfImplements(r KingpinRegistry, tag *Tag, fieldValue refle
 	if _, ok := fieldValue.Addr().Interface().(ValueFlagarizer); ok {
 		allocPtrIfNil(fieldValue)
 		// Do fieldValue.Interface() once more as after alloc the copied value is not changed.
-		if err := invokeCustomValueFlagarizer(r, fieldValue.Addr().Interface().(ValueFlagarizer), tag, fieldValue, name); err != nil {
+		if err := invokeCustomValueFlagarizer(r, fieldValue.Addr().Interface().(ValueFlagarizer), tag, fieldValue); err != nil {
 			return true, err
 		}
 		return true, nil
@@ -368,7 +694,7 @@ func invokeFlagarizersIfImplements(r KingpinRegistry, tag *Tag, fieldValue refle
 	return false, nil
 }
 
-func invokeCustomFlagarizer(r KingpinRegistry, f Flagarizer, tag *Tag, fieldValue reflect.Value, name string) error {
+func invokeCustomFlagarizer(r KingpinRegistry, f Flagarizer, tag *Tag, fieldValue reflect.Value) error {
 	if fieldValue.Kind() != reflect.Ptr {
 		fieldValue = fieldValue.Addr()
 	}
@@ -378,11 +704,11 @@ func invokeCustomFlagarizer(r KingpinRegistry, f Flagarizer, tag *Tag, fieldValu
 	}
 
 	if fieldValue.Elem().MethodByName("Flagarize").IsValid() {
-		return errors.Errorf("flagarize field %q custom Flagarizer is non receiver pointer", name)
+		return errors.New("custom Flagarizer is non receiver pointer")
 	}
 
 	if err := f.Flagarize(r, tag, unsafe.Pointer(fieldValue.Pointer())); err != nil {
-		return errors.Wrapf(err, "custom Flagarizer for field %s", name)
+		return errors.Wrap(err, "custom Flagarizer")
 	}
 	return nil
 }
@@ -396,7 +722,13 @@ func (f *flagarizeValue) String() string {
 	return f.def
 }
 
-func invokeCustomValueFlagarizer(r KingpinRegistry, vf ValueFlagarizer, tag *Tag, fieldValue reflect.Value, name string) error {
+// NewValue returns kingpin.Value parsing values with the given ValueFlagarizer the same way as Flagarize does.
+// The def is the default value of the flag. It is used by code generated with Generate.
+func NewValue(v ValueFlagarizer, def string) kingpin.Value {
+	return &flagarizeValue{ValueFlagarizer: v, def: def}
+}
+
+func invokeCustomValueFlagarizer(r KingpinRegistry, vf ValueFlagarizer, tag *Tag, fieldValue reflect.Value) error {
 	if fieldValue.Kind() != reflect.Ptr {
 		fieldValue = fieldValue.Addr()
 	}
@@ -406,7 +738,7 @@ func invokeCustomValueFlagarizer(r KingpinRegistry, vf ValueFlagarizer, tag *Tag
 	}
 
 	if fieldValue.Elem().MethodByName("Set").IsValid() {
-		return errors.Errorf("flagarize field %q custom ValueFlagarizer is non receiver pointer", name)
+		return errors.New("custom ValueFlagarizer is non receiver pointer")
 	}
 
 	tag.Flag(r).SetValue(&flagarizeValue{ValueFlagarizer: vf, def: tag.DefaultValue})
@@ -422,6 +754,43 @@ type Tag struct {
 	PlaceHolder  string
 	Hidden       bool
 	Required     bool
+	Group        string
+	// Secret marks value that should not be revealed e.g in Dump.
+	Secret bool
+	// Requires are names of flags that have to be set if this flag is set.
+	Requires []string
+	// Conflicts are names of flags that cannot be set if this flag is set.
+	Conflicts []string
+	// OneOf is a name of the group of flags from which at most one can be set.
+	OneOf string
+
+	// Min and Max are inclusive bounds of numeric or duration value (or elements of the slice).
+	Min, Max string
+	// MinLen and MaxLen are inclusive bounds of length of string, slice or map.
+	MinLen, MaxLen string
+	// Pattern is regexp that string value (or elements of the slice) has to fully match.
+	Pattern string
+	// NonEmpty requires string, slice or map to be non-empty.
+	NonEmpty bool
+	// Enum are allowed values of string value (or elements of the slice).
+	Enum []string
+	// Key is the key of the flag value in the config file (see WithConfigFile). Flag name is used if empty.
+	Key string
+	// Arg is true if field is registered as positional argument named Name, instead of flag.
+	Arg bool
+	// Deprecated is a deprecation note e.g "use --web.listen-address instead". It is appended to the help.
+	Deprecated string
+
+	// defaultValues overrides DefaultValue if specified. Used for repeatable values.
+	defaultValues []string
+	// requiredTogether are sets of flags (inherited from nested struct tags) this flag is part of.
+	requiredTogether []*requiredTogether
+}
+
+func (t *Tag) addToRequiredTogether(clauses ...*kingpin.FlagClause) {
+	for _, r := range t.requiredTogether {
+		r.flags = append(r.flags, clauses...)
+	}
 }
 
 func (t *Tag) Flag(r FlagRegisterer) *kingpin.FlagClause {
@@ -432,7 +801,9 @@ func (t *Tag) Flag(r FlagRegisterer) *kingpin.FlagClause {
 	if t.Required {
 		c.Required()
 	}
-	if t.DefaultValue != "" {
+	if len(t.defaultValues) > 0 {
+		c.Default(t.defaultValues...)
+	} else if t.DefaultValue != "" {
 		c.Default(t.DefaultValue)
 	}
 	if t.EnvName != "" {
@@ -444,12 +815,26 @@ func (t *Tag) Flag(r FlagRegisterer) *kingpin.FlagClause {
 	return c
 }
 
+// structHelpProvider returns HelpProvider if struct (or pointer to it) implements it, nil otherwise.
+func structHelpProvider(structVal reflect.Value) HelpProvider {
+	if structVal.CanAddr() && structVal.Addr().CanInterface() {
+		if hp, ok := structVal.Addr().Interface().(HelpProvider); ok {
+			return hp
+		}
+	}
+	if !structVal.CanInterface() {
+		return nil
+	}
+	hp, _ := structVal.Interface().(HelpProvider)
+	return hp
+}
+
 func parseHelpVars(structVal reflect.Value) map[string]*string {
 	helpVars := map[string]*string{}
 	for i := 0; i < structVal.NumField(); i++ {
 		name := structVal.Type().Field(i).Name
 
-		if !strings.HasSuffix(name, "FlagarizeHelp") || structVal.Field(i).Kind() != reflect.String || structVal.Field(i).String() == "" {
+		if !isHelpVar(structVal.Type().Field(i)) || structVal.Field(i).String() == "" {
 			continue
 		}
 		v := structVal.Field(i).String()
@@ -458,20 +843,26 @@ func parseHelpVars(structVal reflect.Value) map[string]*string {
 	return helpVars
 }
 
-func parseTag(field reflect.StructField, helpVar *string, elemSep string) (*Tag, error) {
+// parseTag parses flagarize struct tag of the given field. Options not specified in the struct tag are inherited
+// from the parent nested struct tag (if not nil). Help is looked up using lookupHelp (if not nil) when no
+// help=<help> was specified in the struct tag.
+func parseTag(field reflect.StructField, parent *nestedTag, lookupHelp func(*Tag) *string, elemSep string) (*Tag, error) {
 	val, ok := field.Tag.Lookup(flagTagName)
 	if !ok {
 		return nil, nil
 	}
 
 	f := &Tag{}
+	var hiddenSet, requiredSet bool
+	var keys []string
 	if val != "" {
 		for _, t := range strings.Split(val, elemSep) {
-			kv := strings.Split(t, "=")
+			kv := strings.SplitN(t, "=", 2)
 			if len(kv) == 1 || t == "" {
-				return nil, errors.Errorf("flagarize: expected map-like Tag elements (e.g hidden=true), found non"+
-					" supported format %q for field %q", t, field.Name)
+				return nil, &TagError{Reason: fmt.Sprintf("expected map-like Tag elements (e.g hidden=true), found non"+
+					" supported format %q for field %q", t, field.Name)}
 			}
+			keys = append(keys, kv[0])
 			switch kv[0] {
 			case nameStructTagKey:
 				f.Name = kv[1]
@@ -479,41 +870,108 @@ func parseTag(field reflect.StructField, helpVar *string, elemSep string) (*Tag,
 				f.Help = kv[1]
 			case hiddenStructTagKey:
 				f.Hidden = isTrue(kv[1])
+				hiddenSet = true
 			case requiredStructTagKey:
 				f.Required = isTrue(kv[1])
+				requiredSet = true
 			case defaultStructTagKey:
 				f.DefaultValue = kv[1]
 			case envvarStructTagKey:
 				if kv[1] != strings.ToUpper(kv[1]) {
-					return nil, errors.Errorf("flagarize: environment variable name has to be upper case, but it's not %q for field %q", kv[1], field.Name)
+					return nil, &TagError{Key: kv[0], Reason: fmt.Sprintf("environment variable name has to be upper case, but it's not %q for field %q", kv[1], field.Name)}
 				}
 				f.EnvName = kv[1]
 			case shortStructTagKey:
 				if len(kv[1]) > 1 {
-					return nil, errors.Errorf("flagarize: short cannot be longer than one character got %q for field %q", kv[1], field.Name)
+					return nil, &TagError{Key: kv[0], Reason: fmt.Sprintf("short cannot be longer than one character got %q for field %q", kv[1], field.Name)}
 				}
 				f.Short = rune(kv[1][0])
 			case placeholderStructTagKey:
 				f.PlaceHolder = kv[1]
+			case groupStructTagKey:
+				f.Group = kv[1]
+			case requiresStructTagKey:
+				f.Requires = append(f.Requires, kv[1])
+			case conflictsStructTagKey:
+				f.Conflicts = append(f.Conflicts, kv[1])
+			case oneofStructTagKey:
+				f.OneOf = kv[1]
+			case minStructTagKey:
+				f.Min = kv[1]
+			case maxStructTagKey:
+				f.Max = kv[1]
+			case minlenStructTagKey:
+				f.MinLen = kv[1]
+			case maxlenStructTagKey:
+				f.MaxLen = kv[1]
+			case patternStructTagKey:
+				f.Pattern = kv[1]
+			case nonemptyStructTagKey:
+				f.NonEmpty = isTrue(kv[1])
+			case enumStructTagKey:
+				f.Enum = append(f.Enum, kv[1])
+			case secretStructTagKey:
+				f.Secret = isTrue(kv[1])
+			case keyStructTagKey:
+				f.Key = kv[1]
+			case argStructTagKey:
+				f.Name, f.Arg = kv[1], true
+			case deprecatedStructTagKey:
+				f.Deprecated = kv[1]
 			default:
-				return nil, errors.Errorf("flagarize: expected map-like Tag elements (e.g hidden=true) separated with %s, found but"+
-					" no supported key found %q for field %q; only %v are supported", elemSep, kv[0], field.Name, supportedStuctTagKeys)
+				return nil, &TagError{
+					Key:        kv[0],
+					Suggestion: suggestKey(kv[0], supportedStuctTagKeys),
+					Reason: fmt.Sprintf("expected map-like Tag elements (e.g hidden=true) separated with %s, found but"+
+						" no supported key found %q for field %q; only %v are supported", elemSep, kv[0], field.Name, supportedStuctTagKeys),
+				}
 			}
 		}
 	}
+	if f.Arg {
+		if err := parseArgTag(f, field, keys); err != nil {
+			return nil, err
+		}
+	}
 	if f.Name == "" || f.Name == "-" {
 		f.Name = strings.ToLower(strings.Join(camelcase.Split(field.Name), "_"))
 	}
+	if parent != nil {
+		if !f.Arg {
+			f.Name = parent.namePrefix + f.Name
+		}
+		if f.EnvName != "" {
+			f.EnvName = parent.envPrefix + f.EnvName
+		}
+		if f.Group == "" {
+			f.Group = parent.group
+		}
+		if !hiddenSet {
+			f.Hidden = parent.hidden
+		}
+		if !requiredSet {
+			f.requiredTogether = parent.requiredTogether
+		}
+	}
 	if f.Help == "" {
+		var helpVar *string
+		if lookupHelp != nil {
+			helpVar = lookupHelp(f)
+		}
 		if helpVar == nil {
-			return nil, errors.Errorf("flagarize: no help=<help> in struct Tag for field %q and no help"+
-				" var; help=<help> in struct Tag or \"%s_\" is required for help/usage of the flag; be helpful! :)", field.Name, field.Name)
+			return nil, &TagError{Key: helpStructTagKey, Reason: fmt.Sprintf("no help=<help> in struct Tag for field %q and no help"+
+				" var; help=<help> in struct Tag or \"%s_\" is required for help/usage of the flag; be helpful! :)", field.Name, field.Name)}
 		}
 		f.Help = *helpVar
 	}
 	return f, nil
 }
 
+// isIgnored returns true if the field is explicitly excluded from flagarize with `flagarize:"-"` struct tag.
+func isIgnored(field reflect.StructField) bool {
+	return field.Tag.Get(flagTagName) == "-"
+}
+
 func isTrue(v string) bool {
 	b, err := strconv.ParseBool(v)
 	if err != nil {
@@ -57,7 +57,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on private field \"f\"; it has to be exported", err.Error())
+		testutil.Equals(t, "flagarize: wrong.f: flagarize struct Tag found on private field; it has to be exported", err.Error())
 	})
 	t.Run("flagarize on private field", func(t *testing.T) {
 		type wrong struct {
@@ -68,7 +68,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on private field \"f\"; it has to be exported", err.Error())
+		testutil.Equals(t, "flagarize: wrong.f: flagarize struct Tag found on private field; it has to be exported", err.Error())
 	})
 	t.Run("flagarize on not supported field: map", func(t *testing.T) {
 		type wrong struct {
@@ -79,7 +79,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on not supported type map map[string]int for field \"F\"", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: flagarize struct Tag found on not supported type map[string]int", err.Error())
 	})
 	t.Run("flagarize on pointer for standard type", func(t *testing.T) {
 		type wrong struct {
@@ -90,7 +90,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on not supported type ptr *string for field \"F\"", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: flagarize struct Tag found on not supported type *string", err.Error())
 	})
 	t.Run("flagarize on custom struct that does not have flagarizer method", func(t *testing.T) {
 		type wrong struct {
@@ -101,7 +101,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on not supported type struct struct {} for field \"F\"", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: flagarize struct Tag found on not supported type struct {}", err.Error())
 	})
 	t.Run("flagarize on interface{}", func(t *testing.T) {
 		type wrong struct {
@@ -112,7 +112,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on not supported type interface <nil> for field \"F\"", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: flagarize struct Tag found on not supported type interface {}", err.Error())
 	})
 	t.Run("flagarize on *interface{}", func(t *testing.T) {
 		type wrong struct {
@@ -123,7 +123,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize struct Tag found on not supported type ptr *interface {} for field \"F\"", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: flagarize struct Tag found on not supported type *interface {}", err.Error())
 	})
 	t.Run("custom failing flagarizer", func(t *testing.T) {
 		type wrong struct {
@@ -134,7 +134,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: custom Flagarizer for field F: fail", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: custom Flagarizer: fail", err.Error())
 	})
 	t.Run("custom non receiver pointer Flagarizer", func(t *testing.T) {
 		type wrong struct {
@@ -145,7 +145,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize field \"F\" custom Flagarizer is non receiver pointer", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: custom Flagarizer is non receiver pointer", err.Error())
 	})
 	t.Run("custom non receiver pointer ValueFlagarizer", func(t *testing.T) {
 		type wrong struct {
@@ -156,7 +156,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize field \"F\" custom ValueFlagarizer is non receiver pointer", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F: custom ValueFlagarizer is non receiver pointer", err.Error())
 	})
 	t.Run("duplicate", func(t *testing.T) {
 		type wrong struct {
@@ -168,7 +168,7 @@ func TestFlagarize_Errors(t *testing.T) {
 		app := newTestKingpin(t)
 		err := flagarize.Flagarize(app, w)
 		testutil.NotOk(t, err)
-		testutil.Equals(t, "flagarize: flagarize field F2 was already registered", err.Error())
+		testutil.Equals(t, "flagarize: wrong.F2: flag --f was already registered", err.Error())
 	})
 }
 
@@ -475,6 +475,282 @@ Flags:
 	}
 }
 
+type helpProvidingConfig struct {
+	Field1 string `flagarize:"name=flag1"`
+	Field2 string `flagarize:"name=flag2|help=Tag help wins."`
+	Nested struct {
+		Field3 int `flagarize:"name=nested.flag3"`
+	}
+}
+
+func (c *helpProvidingConfig) FlagarizeHelp(field string) string {
+	if field == "Field1" || field == "Field2" {
+		return "Help from the HelpProvider."
+	}
+	return ""
+}
+
+func TestFlagarize_HelpResolver(t *testing.T) {
+	t.Run("no help anywhere", func(t *testing.T) {
+		type noHelp struct {
+			F string `flagarize:"name=f"`
+		}
+		app := newTestKingpin(t)
+		err := flagarize.Flagarize(app, &noHelp{}, flagarize.WithHelpResolver(func(flagarize.FieldPath, *flagarize.Tag) string { return "" }))
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: noHelp.F: no help=<help> in struct Tag for field \"F\" and no help var; help=<help> in struct Tag or \"F_\" is required for help/usage of the flag; be helpful! :)", err.Error())
+	})
+	t.Run("help from provider and resolver", func(t *testing.T) {
+		var resolved []string
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &helpProvidingConfig{}, flagarize.WithHelpResolver(func(path flagarize.FieldPath, tag *flagarize.Tag) string {
+			resolved = append(resolved, path.String())
+			return fmt.Sprintf("Catalogue help for %s.", tag.Name)
+		})))
+		testutil.Equals(t, []string{"Nested.Field3"}, resolved)
+
+		testutil.Equals(t, "Help from the HelpProvider.", app.GetFlag("flag1").Model().Help)
+		testutil.Equals(t, "Tag help wins.", app.GetFlag("flag2").Model().Help)
+		testutil.Equals(t, "Catalogue help for nested.flag3.", app.GetFlag("nested.flag3").Model().Help)
+	})
+}
+
+func TestFlagarize_ValuesAsDefaults(t *testing.T) {
+	type config struct {
+		F1 string                    `flagarize:"help=1|default=from-tag"`
+		F2 string                    `flagarize:"help=2|default=from-tag"`
+		F3 []string                  `flagarize:"help=3"`
+		F4 time.Duration             `flagarize:"help=4"`
+		F5 map[string]string         `flagarize:"help=5"`
+		F6 *flagarize.TimeOrDuration `flagarize:"help=6"`
+		F7 flagarize.AnchoredRegexp  `flagarize:"help=7"`
+		F8 int                       `flagarize:"help=8|required=true"`
+	}
+	newConfig := func() *config {
+		dur := 5 * time.Minute
+		return &config{
+			F1: "from-value",
+			F3: []string{"a", "b"},
+			F4: 2 * time.Second,
+			F5: map[string]string{"b": "2", "a": "1"},
+			F6: &flagarize.TimeOrDuration{Dur: &dur},
+			F7: flagarize.AnchoredRegexp{Regexp: regexp.MustCompile("^(?:a.+)$")},
+			F8: 1,
+		}
+	}
+
+	t.Run("help", func(t *testing.T) {
+		app := newTestKingpin(t)
+		b := bytes.Buffer{}
+		app.UsageWriter(&b)
+		app.Terminate(func(int) {})
+
+		testutil.Ok(t, flagarize.Flagarize(app, newConfig(), flagarize.WithValuesAsDefaults()))
+		_, err := app.Parse([]string{"--help", "--f8=1"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, `usage: test --f8=F8 [<flags>]
+
+test
+
+Flags:
+  --help             Show context-sensitive help (also try --help-long and
+                     --help-man).
+  --f1="from-value"  1
+  --f2="from-tag"    2
+  --f3=a... ...      3
+  --f4=2s            4
+  --f5=a=1... ...    5
+  --f6=5m0s          6
+  --f7=a.+           7
+  --f8=F8            8
+
+`, b.String())
+	})
+	t.Run("no flags", func(t *testing.T) {
+		c := newConfig()
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, c, flagarize.WithValuesAsDefaults()))
+		_, err := app.Parse([]string{"--f8=2"})
+		testutil.Ok(t, err)
+
+		exp := newConfig()
+		exp.F2 = "from-tag"
+		exp.F8 = 2
+		testutil.Equals(t, exp, c)
+	})
+	t.Run("flags override", func(t *testing.T) {
+		c := newConfig()
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, c, flagarize.WithValuesAsDefaults()))
+		_, err := app.Parse([]string{"--f1=x", "--f3=c", "--f5=c=3", "--f8=2"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, "x", c.F1)
+		testutil.Equals(t, []string{"c"}, c.F3)
+		testutil.Equals(t, map[string]string{"c": "3"}, c.F5)
+	})
+}
+
+func TestFlagarize_NestedStructTags(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `flagarize:"name=cert|help=TLS cert.|envvar=CERT"`
+		Key  string `flagarize:"name=key|help=TLS key."`
+		CA   string `flagarize:"name=ca|help=TLS CA.|required=false|hidden=false"`
+	}
+	type webConfig struct {
+		Address string    `flagarize:"name=address|help=Address.|envvar=ADDRESS"`
+		TLS     tlsConfig `flagarize:"prefix=tls.|envprefix=TLS_|required=true|hidden=true"`
+	}
+	type config struct {
+		Web   webConfig `flagarize:"prefix=web.|envprefix=WEB_|group=web"`
+		Debug bool      `flagarize:"name=debug|help=Debug."`
+	}
+
+	t.Run("inherited options", func(t *testing.T) {
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &config{}))
+
+		for _, tcase := range []struct {
+			name, envvar string
+			hidden       bool
+		}{
+			{name: "web.address", envvar: "WEB_ADDRESS"},
+			{name: "web.tls.cert", envvar: "WEB_TLS_CERT", hidden: true},
+			{name: "web.tls.key", hidden: true},
+			{name: "web.tls.ca"},
+			{name: "debug"},
+		} {
+			f := app.GetFlag(tcase.name)
+			testutil.Assert(t, f != nil, "flag %s not registered", tcase.name)
+			testutil.Equals(t, tcase.envvar, f.Model().Envar)
+			testutil.Equals(t, tcase.hidden, f.Model().Hidden)
+			testutil.Equals(t, false, f.Model().Required)
+		}
+	})
+	t.Run("required together", func(t *testing.T) {
+		for _, tcase := range []struct {
+			input       []string
+			expectedErr string
+		}{
+			{input: []string{}},
+			{input: []string{"--web.tls.ca=ca", "--web.address=a"}},
+			{input: []string{"--web.tls.cert=cert", "--web.tls.key=key"}},
+			{
+				input:       []string{"--web.tls.cert=cert"},
+				expectedErr: "required flag(s) --web.tls.key not provided; flags --web.tls.cert, --web.tls.key of Web.TLS have to be set together, but only --web.tls.cert was set",
+			},
+		} {
+			t.Run(fmt.Sprintf("%v", tcase.input), func(t *testing.T) {
+				app := newTestKingpin(t)
+				testutil.Ok(t, flagarize.Flagarize(app, &config{}))
+				_, err := app.Parse(tcase.input)
+				if tcase.expectedErr != "" {
+					testutil.NotOk(t, err)
+					testutil.Equals(t, tcase.expectedErr, err.Error())
+					return
+				}
+				testutil.Ok(t, err)
+			})
+		}
+	})
+	t.Run("wrong nested tags", func(t *testing.T) {
+		type wrongEnvPrefix struct {
+			Web webConfig `flagarize:"envprefix=web_"`
+		}
+		err := flagarize.Flagarize(newTestKingpin(t), &wrongEnvPrefix{})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: wrongEnvPrefix.Web: environment variable prefix has to be upper case, but it's not \"web_\" for field \"Web\"", err.Error())
+
+		type wrongPrivate struct {
+			web webConfig `flagarize:"prefix=web."`
+		}
+		err = flagarize.Flagarize(newTestKingpin(t), &wrongPrivate{web: webConfig{}})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: wrongPrivate.web: flagarize struct Tag found on private field; it has to be exported", err.Error())
+	})
+}
+
+func TestFlagarize_Constraints(t *testing.T) {
+	type config struct {
+		Cert     string `flagarize:"name=tls.cert|help=TLS cert.|requires=tls.key"`
+		Key      string `flagarize:"name=tls.key|help=TLS key.|requires=tls.cert"`
+		Insecure bool   `flagarize:"name=insecure|help=Insecure.|conflicts=tls.cert|conflicts=tls.key"`
+		File     string `flagarize:"name=file|help=File.|oneof=input|envvar=FILE"`
+		URL      string `flagarize:"name=url|help=URL.|oneof=input"`
+		Stdin    bool   `flagarize:"name=stdin|help=Stdin.|oneof=input"`
+		Verbose  bool   `flagarize:"name=verbose|help=Verbose.|conflicts=quiet"`
+		Quiet    bool   `flagarize:"name=quiet|help=Quiet.|conflicts=verbose"`
+	}
+
+	for _, tcase := range []struct {
+		input       []string
+		env         map[string]string
+		expectedErr string
+	}{
+		{input: []string{}},
+		{input: []string{"--tls.cert=c", "--tls.key=k", "--file=f"}},
+		{input: []string{"--insecure", "--url=u"}},
+		{
+			input:       []string{"--tls.cert=c"},
+			expectedErr: "flag --tls.cert requires flag --tls.key to be set",
+		},
+		{
+			input: []string{"--tls.key=k", "--insecure", "--file=f", "--stdin"},
+			expectedErr: "3 error(s) occurred:\n" +
+				"* flag --tls.key requires flag --tls.cert to be set\n" +
+				"* flag --insecure conflicts with flag --tls.key; only one can be set\n" +
+				"* only one of flags --file, --url, --stdin (input) can be set, got --file, --stdin",
+		},
+		{
+			input:       []string{"--verbose", "--quiet"},
+			expectedErr: "flag --verbose conflicts with flag --quiet; only one can be set",
+		},
+		{
+			input:       []string{"--url=u"},
+			env:         map[string]string{"FILE": "f"},
+			expectedErr: "only one of flags --file, --url, --stdin (input) can be set, got --file, --url",
+		},
+	} {
+		t.Run(fmt.Sprintf("%v", tcase.input), func(t *testing.T) {
+			for k, v := range tcase.env {
+				testutil.Ok(t, os.Setenv(k, v))
+				defer func(k string) { testutil.Ok(t, os.Unsetenv(k)) }(k)
+			}
+
+			app := newTestKingpin(t)
+			testutil.Ok(t, flagarize.Flagarize(app, &config{}))
+			_, err := app.Parse(tcase.input)
+			if tcase.expectedErr != "" {
+				testutil.NotOk(t, err)
+				testutil.Equals(t, tcase.expectedErr, err.Error())
+				return
+			}
+			testutil.Ok(t, err)
+		})
+	}
+	t.Run("unknown flag", func(t *testing.T) {
+		type wrongRequires struct {
+			Cert string `flagarize:"name=tls.cert|help=TLS cert.|requires=tls.key"`
+		}
+		err := flagarize.Flagarize(newTestKingpin(t), &wrongRequires{})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: flag --tls.cert requires flag --tls.key that is not registered", err.Error())
+	})
+	t.Run("command flag referencing application flag", func(t *testing.T) {
+		type cmdConfig struct {
+			Insecure bool `flagarize:"name=insecure|help=Insecure."`
+			Run      struct {
+				Cert string `flagarize:"name=tls.cert|help=TLS cert.|conflicts=insecure"`
+			} `flagarize:"cmd=run|help=Run."`
+		}
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &cmdConfig{}))
+
+		_, err := app.Parse([]string{"--insecure", "run", "--tls.cert=c"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flag --tls.cert conflicts with flag --insecure; only one can be set", err.Error())
+	})
+}
+
 func ExampleFlagarize() {
 	// Create new kingpin app as usual.
 	a := kingpin.New(filepath.Base(os.Args[0]), "<Your CLI description>")
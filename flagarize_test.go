@@ -136,11 +136,11 @@ func testParseTag(t *testing.T, input interface{}, sep string) {
 		err error
 	}{
 		{},
-		{err: errors.New("flagarize: no help=<help> in struct Tag for field \"wrongNoHelp1\" and no help var; help=<help> in struct Tag or \"wrongNoHelp1_\" is required for help/usage of the flag; be helpful! :)")},
-		{err: errors.New("flagarize: expected map-like Tag elements (e.g hidden=true), found non supported format \"wrong\" for field \"wrongFormat1\"")},
-		{err: errors.New("flagarize: expected map-like Tag elements (e.g hidden=true), found non supported format \"\" for field \"wrongFormat2\"")},
-		{err: errors.New("flagarize: expected map-like Tag elements (e.g hidden=true), found non supported format \"\" for field \"wrongFormat3\"")},
-		{err: errors.New("flagarize: no help=<help> in struct Tag for field \"wrongNoHelp3\" and no help var; help=<help> in struct Tag or \"wrongNoHelp3_\" is required for help/usage of the flag; be helpful! :)")},
+		{err: errors.New("no help=<help> in struct Tag for field \"wrongNoHelp1\" and no help var; help=<help> in struct Tag or \"wrongNoHelp1_\" is required for help/usage of the flag; be helpful! :)")},
+		{err: errors.New("expected map-like Tag elements (e.g hidden=true), found non supported format \"wrong\" for field \"wrongFormat1\"")},
+		{err: errors.New("expected map-like Tag elements (e.g hidden=true), found non supported format \"\" for field \"wrongFormat2\"")},
+		{err: errors.New("expected map-like Tag elements (e.g hidden=true), found non supported format \"\" for field \"wrongFormat3\"")},
+		{err: errors.New("no help=<help> in struct Tag for field \"wrongNoHelp3\" and no help var; help=<help> in struct Tag or \"wrongNoHelp3_\" is required for help/usage of the flag; be helpful! :)")},
 		{tag: &Tag{Name: "no_name", Help: "help"}},
 		{tag: &Tag{Name: "no_name2", Help: "help"}},
 		{tag: &Tag{Name: "case1", Help: "help"}},
@@ -149,15 +149,15 @@ func testParseTag(t *testing.T, input interface{}, sep string) {
 		{},
 		{tag: &Tag{Name: "case2b", Help: "Some runtime evaluated help2 in flagarize."}},
 		{},
-		{err: errors.Errorf("flagarize: expected map-like Tag elements (e.g hidden=true) separated with %s, found but no supported key found \"nonexistingfield\" for field \"wrongFormat4\"; only [name help hidden required default envvar short placeholder] are supported", sep)},
-		{err: errors.New("flagarize: expected map-like Tag elements (e.g hidden=true), found non supported format \"wrongformat\" for field \"wrongFormat5\"")},
+		{err: errors.Errorf("expected map-like Tag elements (e.g hidden=true) separated with %s, found but no supported key found \"nonexistingfield\" for field \"wrongFormat4\"; only [name help hidden required default envvar short placeholder group requires conflicts oneof min max minlen maxlen pattern nonempty enum secret arg key deprecated] are supported", sep)},
+		{err: errors.New("expected map-like Tag elements (e.g hidden=true), found non supported format \"wrongformat\" for field \"wrongFormat5\"")},
 		{tag: &Tag{Name: "case3", Help: "help", Hidden: true}},
 		{tag: &Tag{Name: "case4", Help: "help", Required: true}},
-		{err: errors.New("flagarize: no help=<help> in struct Tag for field \"wrongNoHelp6\" and no help var; help=<help> in struct Tag or \"wrongNoHelp6_\" is required for help/usage of the flag; be helpful! :)")},
+		{err: errors.New("no help=<help> in struct Tag for field \"wrongNoHelp6\" and no help var; help=<help> in struct Tag or \"wrongNoHelp6_\" is required for help/usage of the flag; be helpful! :)")},
 		{tag: &Tag{Name: "case5", Help: "help", DefaultValue: "some default value ms 2213"}},
-		{err: errors.New("flagarize: environment variable name has to be upper case, but it's not \"lowerCASEnotallowed\" for field \"envVarWrong\"")},
+		{err: errors.New("environment variable name has to be upper case, but it's not \"lowerCASEnotallowed\" for field \"envVarWrong\"")},
 		{tag: &Tag{Name: "case6", Help: "help", EnvName: "SOME_ENVVAR"}},
-		{err: errors.New("flagarize: short cannot be longer than one character got \"tooLong\" for field \"shortWrong\"")},
+		{err: errors.New("short cannot be longer than one character got \"tooLong\" for field \"shortWrong\"")},
 		{tag: &Tag{Name: "case7", Help: "help", Short: 'l'}},
 		{tag: &Tag{Name: "case8", Help: "help", PlaceHolder: "<something>"}},
 		{tag: &Tag{Name: "case9", Help: "help", Required: true, Hidden: true, Short: 'z', EnvName: "LOL", DefaultValue: "some", PlaceHolder: "<something2>"}},
@@ -173,7 +173,7 @@ func testParseTag(t *testing.T, input interface{}, sep string) {
 		field := val.Type().Field(i)
 
 		t.Run(field.Name, func(t *testing.T) {
-			tag, err := parseTag(field, helpVars[field.Name], sep)
+			tag, err := parseTag(field, nil, func(*Tag) *string { return helpVars[field.Name] }, sep)
 			if expected[i].err != nil {
 				testutil.NotOk(t, err)
 				testutil.Equals(t, expected[i].err.Error(), err.Error())
@@ -0,0 +1,14 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Command flagarize-vet reports errors of `flagarize:"..."` struct tags. It can be run directly or by go vet:
+//
+//	go vet -vettool=$(which flagarize-vet) ./...
+package main
+
+import (
+	"github.com/bwplotka/flagarize/flagarizecheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() { singlechecker.Main(flagarizecheck.Analyzer) }
@@ -0,0 +1,128 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package flagarizecheck defines an Analyzer reporting errors of `flagarize:"..."` struct tags, that Flagarize would
+// return on program start (see gen.Check).
+package flagarizecheck
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/gen"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+const doc = `check flagarize struct tags
+
+The flagarize analyzer reports errors of structs with flagarize struct tags, that flagarize.Flagarize would
+return on program start: malformed or unknown struct tag keys, lower case environment variables, too long shorts,
+duplicated flag names (also across nested structs), missing help, unsupported field types and ValueFlagarizer or
+Flagarizer implemented with non pointer receivers.
+
+Types parsed with the flagarize.Value option are not known statically, so they have to be listed with the -values
+flag, otherwise they are reported as unsupported.`
+
+// Analyzer reports errors of flagarize struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name: "flagarize",
+	Doc:  doc,
+	Run:  run,
+}
+
+var (
+	noHelp bool
+	values string
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&noHelp, "nohelp", false, "do not report fields without help e.g if flagarize.WithHelpResolver is used")
+	Analyzer.Flags.StringVar(&values, "values", "", "comma separated types registered with flagarize.Value option, qualified with package path e.g github.com/go-kit/log/level.Level")
+}
+
+// valueTypes returns function returning true for types given by -values flag, nil if none.
+func valueTypes() func(types.Type) bool {
+	if values == "" {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, v := range strings.Split(values, ",") {
+		names[strings.TrimSpace(v)] = true
+	}
+	return func(t types.Type) bool {
+		return names[types.TypeString(t, func(p *types.Package) string { return p.Path() })]
+	}
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	files := map[*token.File]bool{}
+	for _, f := range pass.Files {
+		files[pass.Fset.File(f.Pos())] = true
+	}
+
+	// Check only top level structs, so errors of nested structs are reported once with flag names they have in
+	// the top level struct.
+	var structs []*types.TypeName
+	nested := map[types.Type]bool{}
+	for _, obj := range pass.TypesInfo.Defs {
+		tn, ok := obj.(*types.TypeName)
+		if !ok || tn.IsAlias() || !hasFlagarizeTags(tn.Type()) {
+			continue
+		}
+		structs = append(structs, tn)
+		markNested(tn.Type().Underlying().(*types.Struct), nested)
+	}
+	sort.Slice(structs, func(i, j int) bool { return structs[i].Pos() < structs[j].Pos() })
+
+	reported := map[token.Pos]bool{}
+	isValue := valueTypes()
+	for _, tn := range structs {
+		if nested[tn.Type()] {
+			continue
+		}
+		gen.Check(pass.Pkg, tn.Type(), isValue, func(pos token.Pos, err error) {
+			if reported[pos] || !files[pass.Fset.File(pos)] {
+				return
+			}
+			var tagErr *flagarize.TagError
+			if noHelp && errors.As(err, &tagErr) && tagErr.Key == "help" {
+				return
+			}
+			reported[pos] = true
+			pass.Reportf(pos, "%s", err)
+		})
+	}
+	return nil, nil
+}
+
+// hasFlagarizeTags returns true if t is a struct with at least one field with flagarize struct tag.
+func hasFlagarizeTags(t types.Type) bool {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if _, ok := reflect.StructTag(st.Tag(i)).Lookup("flagarize"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// markNested marks types of struct fields of the struct (recursively).
+func markNested(st *types.Struct, nested map[types.Type]bool) {
+	for i := 0; i < st.NumFields(); i++ {
+		t := st.Field(i).Type()
+		fst, ok := t.Underlying().(*types.Struct)
+		if !ok || nested[t] {
+			continue
+		}
+		nested[t] = true
+		markNested(fst, nested)
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarizecheck_test
+
+import (
+	"testing"
+
+	"github.com/bwplotka/flagarize/flagarizecheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), flagarizecheck.Analyzer, "a")
+}
+
+func TestAnalyzer_Values(t *testing.T) {
+	if err := flagarizecheck.Analyzer.Flags.Set("values", "values.level, values.point"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = flagarizecheck.Analyzer.Flags.Set("values", "") }()
+
+	analysistest.Run(t, analysistest.TestData(), flagarizecheck.Analyzer, "values")
+}
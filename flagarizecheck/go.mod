@@ -0,0 +1,18 @@
+module github.com/bwplotka/flagarize/flagarizecheck
+
+go 1.23.0
+
+require (
+	// v0.10.0 is the first flagarize release with the gen package, so this module is released after it.
+	github.com/bwplotka/flagarize v0.10.0
+	github.com/pkg/errors v0.9.1
+	golang.org/x/tools v0.34.0
+)
+
+require (
+	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751 // indirect
+	github.com/alecthomas/units v0.0.0-20190924025748-f65c72e2690d // indirect
+	golang.org/x/mod v0.25.0 // indirect
+	golang.org/x/sync v0.15.0 // indirect
+	gopkg.in/alecthomas/kingpin.v2 v2.2.6 // indirect
+)
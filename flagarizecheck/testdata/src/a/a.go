@@ -0,0 +1,43 @@
+package a
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/bwplotka/flagarize"
+)
+
+type valueSet struct{}
+
+func (valueSet) Set(string) error { return nil }
+
+type valueFlagarize struct{}
+
+func (valueFlagarize) Flagarize(flagarize.FlagRegisterer, *flagarize.Tag, unsafe.Pointer) error {
+	return nil
+}
+
+type pointerSet struct{}
+
+func (*pointerSet) Set(string) error { return nil }
+
+type WebConfig struct {
+	Listen  string        `flagarize:"name=listen|help=Listen address."`
+	Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|requred=true"` // want `Config.Web.Timeout: expected map-like Tag elements .* no supported key found "requred" .*; did you mean "required"\?`
+}
+
+type Config struct {
+	Web      WebConfig      `flagarize:"prefix=web."`
+	Listen   string         `flagarize:"name=web.listen|help=Listen."`    // want `Config.Listen: flag --web.listen was already registered`
+	Env      string         `flagarize:"name=env|help=Env.|envvar=env"`   // want `environment variable name has to be upper case`
+	Short    string         `flagarize:"name=short|help=Short.|short=sh"` // want `short cannot be longer than one character`
+	NoHelp   string         `flagarize:"name=no-help"`                    // want `Config.NoHelp: no help=<help> in struct Tag`
+	Chan     chan int       `flagarize:"name=chan|help=Chan."`            // want `Config.Chan: flagarize struct Tag found on not supported type chan int`
+	Set      valueSet       `flagarize:"name=set|help=Set."`              // want `Config.Set: custom ValueFlagarizer is non receiver pointer`
+	Flag     valueFlagarize `flagarize:"name=flag|help=Flag."`            // want `Config.Flag: custom Flagarizer is non receiver pointer`
+	Pointer  pointerSet     `flagarize:"name=pointer|help=Pointer.|requires=env"`
+	Provided string         `flagarize:"name=provided"`
+	Ignored  chan int       `flagarize:"-"`
+
+	ProvidedFlagarizeHelp string
+}
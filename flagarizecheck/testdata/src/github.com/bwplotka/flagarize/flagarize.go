@@ -0,0 +1,12 @@
+// Package flagarize is a stub of flagarize package with interfaces used by the analyzer.
+package flagarize
+
+import "unsafe"
+
+type FlagRegisterer interface{}
+
+type Tag struct{}
+
+type Flagarizer interface {
+	Flagarize(r FlagRegisterer, tag *Tag, ptr unsafe.Pointer) error
+}
@@ -0,0 +1,16 @@
+package values
+
+type level int
+
+type point struct{ X, Y int }
+
+type other int
+
+type Config struct {
+	Level   level   `flagarize:"name=level|help=Level."`
+	Point   point   `flagarize:"name=point|help=Point."`
+	Points  []point `flagarize:"name=points|help=Points."` // want `Config.Points: flagarize struct Tag found on not supported type \[\]point`
+	Other   other   `flagarize:"name=other|help=Other."`   // want `Config.Other: flagarize struct Tag found on not supported type other`
+	Levels  level   `flagarize:"name=level|help=Again."`   // want `Config.Levels: flag --level was already registered`
+	Ignored other   `flagarize:"-"`
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alecthomas/units"
+)
+
+// ValueFormatter can be implemented by ValueFlagarizer to render its value in the canonical form, so the one
+// that Set accepts. If not implemented, fmt.Stringer is used (if implemented).
+//
+// For an example see: `./timeduration.go` or `./regexp.go`.
+type ValueFormatter interface {
+	// FormatValue returns value formatted as flag value. It should be possible to pass it to Set to get equal value.
+	FormatValue() string
+}
+
+// isZeroValue returns true if value is the zero value of its type. Pointers are dereferenced and empty slices
+// and maps are treated as zero.
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil() || isZeroValue(v.Elem())
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// formatValue renders the field value the way it would be typed as flag value. Repeatable values (slices and maps)
+// are rendered as many values. Nil is returned for zero value.
+func formatValue(v reflect.Value) []string {
+	if !v.IsValid() || isZeroValue(v) {
+		return nil
+	}
+	if v.CanAddr() && v.Addr().CanInterface() {
+		if f, ok := v.Addr().Interface().(ValueFormatter); ok {
+			return []string{f.FormatValue()}
+		}
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	switch i := v.Interface().(type) {
+	case ValueFormatter:
+		return []string{i.FormatValue()}
+	case string:
+		return []string{i}
+	case bool:
+		return []string{strconv.FormatBool(i)}
+	case float32:
+		return []string{strconv.FormatFloat(float64(i), 'g', -1, 32)}
+	case float64:
+		return []string{strconv.FormatFloat(i, 'g', -1, 64)}
+	case time.Duration:
+		return []string{i.String()}
+	case net.IP:
+		return []string{i.String()}
+	case units.Base2Bytes:
+		return []string{i.String()}
+	case *net.TCPAddr:
+		return []string{i.String()}
+	case *url.URL:
+		return []string{i.String()}
+	case *os.File:
+		return []string{i.Name()}
+	case map[string]string:
+		keys := make([]string, 0, len(i))
+		for k := range i {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		ret := make([]string, 0, len(i))
+		for _, k := range keys {
+			ret = append(ret, fmt.Sprintf("%s=%s", k, i[k]))
+		}
+		return ret
+	case fmt.Stringer:
+		return []string{i.String()}
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{strconv.FormatInt(v.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []string{strconv.FormatUint(v.Uint(), 10)}
+	case reflect.Slice:
+		ret := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			e := formatValue(v.Index(i))
+			if len(e) == 0 {
+				// Zero elements still have to be passed as a value.
+				e = formatZeroValue(v.Index(i))
+			}
+			ret = append(ret, e...)
+		}
+		return ret
+	}
+	return []string{fmt.Sprint(v.Interface())}
+}
+
+// formatZeroValue renders zero value of the element in the form accepted by flag parsers.
+func formatZeroValue(v reflect.Value) []string {
+	switch v.Kind() {
+	case reflect.String:
+		return []string{""}
+	case reflect.Bool:
+		return []string{"false"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := v.Interface().(time.Duration); ok {
+			return []string{"0s"}
+		}
+		return []string{"0"}
+	}
+	return []string{""}
+}
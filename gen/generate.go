@@ -0,0 +1,706 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package gen generates plain kingpin registration code for structs with `flagarize:"..."` struct tags and checks
+// those tags based on Go types only. It's separate from the flagarize package, so programs using flagarize do not link
+// go/parser and go/types.
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/pkg/errors"
+)
+
+const (
+	flagarizePkgPath = "github.com/bwplotka/flagarize"
+	flagTagName      = "flagarize"
+	// generatedHeader marks files generated by Generate. Those files are not read by Generate, so stale generated
+	// code does not break the generation.
+	generatedHeader = "// Code generated by flagarize-gen. DO NOT EDIT."
+)
+
+// builtinVarMethods are kingpin *Var methods used for types natively supported by flagarize keyed by type with full
+// package path.
+var builtinVarMethods = map[string]string{
+	"string":                                 "StringVar",
+	"bool":                                   "BoolVar",
+	"uint":                                   "UintVar",
+	"uint8":                                  "Uint8Var",
+	"byte":                                   "Uint8Var",
+	"uint16":                                 "Uint16Var",
+	"uint32":                                 "Uint32Var",
+	"uint64":                                 "Uint64Var",
+	"int":                                    "IntVar",
+	"int8":                                   "Int8Var",
+	"int16":                                  "Int16Var",
+	"int32":                                  "Int32Var",
+	"rune":                                   "Int32Var",
+	"int64":                                  "Int64Var",
+	"float32":                                "Float32Var",
+	"float64":                                "Float64Var",
+	"time.Duration":                          "DurationVar",
+	"net.IP":                                 "IPVar",
+	"github.com/alecthomas/units.Base2Bytes": "BytesVar",
+	"*net.TCPAddr":                           "TCPVar",
+	"*net/url.URL":                           "URLVar",
+	"*os.File":                               "FileVar",
+	"[]bool":                                 "BoolListVar",
+	"[]string":                               "StringsVar",
+	"[]int":                                  "IntsVar",
+	"[]int8":                                 "Int8ListVar",
+	"[]int16":                                "Int16ListVar",
+	"[]int32":                                "Int32ListVar",
+	"[]rune":                                 "Int32ListVar",
+	"[]int64":                                "Int64ListVar",
+	"[]uint":                                 "UintsVar",
+	"[]uint8":                                "Uint8ListVar",
+	"[]byte":                                 "Uint8ListVar",
+	"[]uint16":                               "Uint16ListVar",
+	"[]uint32":                               "Uint32ListVar",
+	"[]uint64":                               "Uint64ListVar",
+	"[]float32":                              "Float32ListVar",
+	"[]float64":                              "Float64ListVar",
+	"[]time.Duration":                        "DurationListVar",
+	"[]net.IP":                               "IPListVar",
+	"[]*net.TCPAddr":                         "TCPListVar",
+	"[]*net/url.URL":                         "URLListVar",
+	"map[string]string":                      "StringMapVar",
+}
+
+// Generate writes Go source file with registration functions for the given struct types from the Go package in the
+// directory dir. For each type T function FlagarizeT(r flagarize.Registry, c *T) error is generated
+// (flagarizeT for unexported types), registering the same flags, positional arguments and commands with plain kingpin
+// calls as flagarize.Flagarize(r, c) does without options.
+//
+// Generated code covers only a subset of struct tag keys, as it does not check flags after parse: keys requiring
+// such checks (requires, conflicts, oneof, min, max, minlen, maxlen, pattern, nonempty, enum and required of nested
+// structs) as well as help vars and HelpProvider return error. Other struct tags are parsed when generating, so all
+// errors Flagarize would return are returned by Generate instead. Files generated by Generate are not read.
+func Generate(w io.Writer, dir string, typeNames ...string) error {
+	if len(typeNames) == 0 {
+		return errors.New("flagarize: no types to generate")
+	}
+	g, err := newGenerator(dir)
+	if err != nil {
+		return errors.Wrap(err, "flagarize")
+	}
+
+	var merr flagarize.MultiError
+	g.report = func(p token.Pos, err error) { merr.Append(errors.Wrap(err, g.position(p))) }
+	var funcs bytes.Buffer
+	for _, n := range typeNames {
+		merr.Append(g.genType(&funcs, n))
+	}
+	if err := merr.Err(); err != nil {
+		return errors.Wrap(err, "flagarize")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n\npackage %s\n\nimport (\n", generatedHeader, g.pkg.Name())
+	var std, other []string
+	for p := range g.imports {
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			other = append(other, p)
+			continue
+		}
+		std = append(std, p)
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for i, paths := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 {
+			b.WriteString("\n")
+		}
+		for _, p := range paths {
+			if name := g.imports[p]; name != path.Base(p) {
+				fmt.Fprintf(&b, "\t%s %q\n", name, p)
+				continue
+			}
+			fmt.Fprintf(&b, "\t%q\n", p)
+		}
+	}
+	b.WriteString(")\n")
+	b.Write(funcs.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "flagarize: format generated code")
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	fset    *token.FileSet
+	pkg     *types.Package
+	pkgPath string
+
+	// flagarizer is the Flagarizer interface, nil if package does not depend on flagarize (so nothing can
+	// implement it).
+	flagarizer      *types.Interface
+	valueFlagarizer *types.Interface
+	// imports are packages used by generated code keyed by path.
+	imports map[string]string
+	cmds    int
+
+	// check is true if structs are only checked (see Check), so keys requiring runtime machinery are allowed.
+	check bool
+	// values returns true for types registered with the flagarize.Value option, nil if none. Used only by Check.
+	values func(types.Type) bool
+	// report reports error of the field at the given position.
+	report func(token.Pos, error)
+}
+
+// Check reports errors Flagarize would return for the struct type t from the package pkg, based only on Go types of
+// its fields (e.g malformed struct tags, duplicated flags, unsupported types or ValueFlagarizer and Flagarizer
+// implemented with non pointer receivers). Each error is reported with the position of the field. Help is
+// assumed to be provided by help vars or HelpProvider if struct has them, otherwise TagError with help key is
+// reported. Fields of types for which values returns true are assumed to be registered with the flagarize.Value
+// option, so they are neither reported as unsupported nor treated as nested structs; values can be nil.
+// It is used by the flagarizecheck analyzer.
+func Check(pkg *types.Package, t types.Type, values func(types.Type) bool, report func(token.Pos, error)) {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+	g := &generator{pkg: pkg, pkgPath: pkg.Path(), imports: map[string]string{}, check: true, values: values, report: report}
+	g.initInterfaces()
+
+	root := types.TypeString(t, types.RelativeTo(pkg))
+	if n, ok := t.(*types.Named); ok {
+		root = n.Obj().Name()
+	}
+	g.genStruct(ioutil.Discard, "r", t, st, "c", root, nil, &flagarize.StructTags{}, newGenScope())
+}
+
+func newGenerator(dir string) (*generator, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read package in %s", dir)
+	}
+
+	g := &generator{fset: token.NewFileSet(), imports: map[string]string{flagarizePkgPath: "flagarize"}}
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if isGenerated(f) {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	g.pkgPath = packagePath(dir, bp.Name)
+	conf := types.Config{
+		Importer: importer.ForCompiler(g.fset, "source", nil),
+		// Package can use code that is not generated yet, so type errors are ignored. Types of struct fields are
+		// still resolved.
+		Error: func(error) {},
+	}
+	g.pkg, _ = conf.Check(g.pkgPath, g.fset, files, nil)
+	if g.pkg == nil {
+		return nil, errors.Errorf("type check package in %s", dir)
+	}
+
+	g.initInterfaces()
+	return g, nil
+}
+
+// initInterfaces looks up Flagarizer and ValueFlagarizer interfaces for the package.
+func (g *generator) initInterfaces() {
+	flagarizePkg := g.pkg
+	if g.pkg.Path() != flagarizePkgPath {
+		flagarizePkg = findImport(g.pkg, flagarizePkgPath, map[*types.Package]bool{})
+	}
+	if flagarizePkg != nil {
+		if obj := flagarizePkg.Scope().Lookup("Flagarizer"); obj != nil {
+			g.flagarizer, _ = obj.Type().Underlying().(*types.Interface)
+		}
+	}
+	set := types.NewFunc(token.NoPos, nil, "Set", types.NewSignature(nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "s", types.Typ[types.String])),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())), false))
+	g.valueFlagarizer = types.NewInterfaceType([]*types.Func{set}, nil).Complete()
+}
+
+// isGenerated returns true if file was generated by Generate.
+func isGenerated(f *ast.File) bool {
+	for _, c := range f.Comments {
+		if c.Pos() > f.Package {
+			break
+		}
+		for _, l := range c.List {
+			if l.Text == generatedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packagePath returns import path of the package in the directory based on the closest go.mod file.
+// Package name is returned if there is no go.mod file.
+func packagePath(dir, name string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if f, err := os.Open(filepath.Join(d, "go.mod")); err == nil {
+			defer func() { _ = f.Close() }()
+			s := bufio.NewScanner(f)
+			for s.Scan() {
+				fields := strings.Fields(s.Text())
+				if len(fields) == 2 && fields[0] == "module" {
+					rel, err := filepath.Rel(d, dir)
+					if err != nil {
+						return name
+					}
+					return path.Join(strings.Trim(fields[1], `"`), filepath.ToSlash(rel))
+				}
+			}
+			return name
+		}
+		if filepath.Dir(d) == d {
+			return name
+		}
+	}
+}
+
+func findImport(pkg *types.Package, path string, seen map[*types.Package]bool) *types.Package {
+	for _, p := range pkg.Imports() {
+		if p.Path() == path {
+			return p
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if f := findImport(p, path, seen); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// genScope tracks flags and positional arguments registered in the single registry (application or command).
+type genScope struct {
+	flags map[string]bool
+	args  []genArg
+}
+
+type genArg struct {
+	name                 string
+	required, cumulative bool
+}
+
+func newGenScope() *genScope { return &genScope{flags: map[string]bool{}} }
+
+func (g *generator) genType(w io.Writer, name string) error {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return errors.Errorf("type %s not found in package %s", name, g.pkgPath)
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return errors.Errorf("type %s is not a struct", name)
+	}
+
+	var body bytes.Buffer
+	g.genStruct(&body, "r", obj.Type(), st, "c", name, nil, &flagarize.StructTags{}, newGenScope())
+
+	fn := "Flagarize" + name
+	if !obj.Exported() {
+		r, n := utf8.DecodeRuneInString(name)
+		fn = "flagarize" + string(unicode.ToUpper(r)) + name[n:]
+	}
+	fmt.Fprintf(w, "\n// %s registers flags of %s the same as flagarize.Flagarize(r, c) without options. Only the subset of\n"+
+		"// struct tag keys without checks after parse is supported.\n", fn, name)
+	fmt.Fprintf(w, "func %s(r flagarize.Registry, c *%s) error {\n%sreturn nil\n}\n", fn, name, body.String())
+	return nil
+}
+
+// genStruct writes registration of fields of the struct st (type t) available under expr in the registry reg.
+// It mirrors parseStruct of the flagarize package. Errors are reported with report.
+func (g *generator) genStruct(w io.Writer, reg string, t types.Type, st *types.Struct, expr, root string, path flagarize.FieldPath, tags *flagarize.StructTags, sc *genScope) {
+	helpProvider := g.hasMethod(t, "FlagarizeHelp")
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		field := reflect.StructField{Name: f.Name(), Tag: reflect.StructTag(st.Tag(i)), Anonymous: f.Embedded()}
+		if !f.Exported() {
+			field.PkgPath = g.pkgPath
+		}
+		fieldPath := path.Child(f.Name())
+		fieldExpr := expr + "." + f.Name()
+		pos := flagarize.FieldPos{Path: append(flagarize.FieldPath{root}, fieldPath...), Location: g.location(t, f.Name())}
+		fail := func(err error) { g.report(f.Pos(), withFieldPos(err, pos)) }
+		if field.Tag.Get(flagTagName) == "-" {
+			continue
+		}
+
+		fst, isStruct := f.Type().Underlying().(*types.Struct)
+		if isStruct && !g.isValue(f.Type()) && !g.implements(f.Type(), g.flagarizer) && !g.implements(f.Type(), g.valueFlagarizer) {
+			nested, err := tags.Nested(field)
+			if err != nil {
+				fail(err)
+				continue
+			}
+			if nested != nil {
+				if _, tagged := field.Tag.Lookup(flagTagName); tagged && field.PkgPath != "" && !field.Anonymous {
+					fail(&flagarize.PrivateFieldError{})
+					continue
+				}
+				if nested.Required && !g.check {
+					fail(&flagarize.TagError{Key: "required", Reason: fmt.Sprintf("required nested struct %q is not supported by generated code", f.Name())})
+					continue
+				}
+				if nested.Command != "" {
+					g.genCommand(w, reg, f.Type(), fst, fieldExpr, root, fieldPath, nested)
+					continue
+				}
+				if field.PkgPath == "" || field.Anonymous {
+					g.genStruct(w, reg, f.Type(), fst, fieldExpr, root, fieldPath, nested.Fields, sc)
+				}
+				continue
+			}
+		}
+
+		runtimeHelp := false
+		tag, err := tags.Field(field, func(*flagarize.Tag) *string {
+			runtimeHelp = helpProvider || hasHelpVar(st, f.Name())
+			if runtimeHelp && g.check {
+				noHelp := ""
+				return &noHelp
+			}
+			return nil
+		})
+		if err != nil {
+			if runtimeHelp {
+				err = &flagarize.TagError{Key: "help", Reason: fmt.Sprintf("help vars and HelpProvider are not supported by generated code; help=<help> in struct Tag is required for field %q", f.Name())}
+			}
+			fail(err)
+			continue
+		}
+		if tag == nil {
+			if isStruct && (field.PkgPath == "" || field.Anonymous) {
+				g.genStruct(w, reg, f.Type(), fst, fieldExpr, root, fieldPath, tags, sc)
+			}
+			continue
+		}
+		if err := g.genField(w, reg, field, f.Type(), fieldExpr, pos, tag, sc); err != nil {
+			fail(err)
+		}
+	}
+}
+
+// genCommand writes registration of the command from the nested struct with cmd=<name> struct tag. It mirrors
+// parseCommand.
+func (g *generator) genCommand(w io.Writer, reg string, t types.Type, st *types.Struct, expr, root string, path flagarize.FieldPath, n *flagarize.NestedStructTag) {
+	g.cmds++
+	cmd := fmt.Sprintf("cmd%d", g.cmds)
+
+	var body bytes.Buffer
+	g.genStruct(&body, cmd, t, st, expr, root, path, n.Fields, newGenScope())
+	clause := fmt.Sprintf("%s.Command(%q, %q)", reg, n.Command, n.CommandHelp)
+	if n.Hidden {
+		clause += ".Hidden()"
+	}
+	if body.Len() == 0 {
+		fmt.Fprintf(w, "%s\n", clause)
+		return
+	}
+	fmt.Fprintf(w, "%s := %s\n%s", cmd, clause, body.String())
+}
+
+// runtimeOnlyKeys returns struct tag keys used in the tag that require runtime machinery of Flagarize.
+func runtimeOnlyKeys(t *flagarize.Tag) []string {
+	var keys []string
+	for _, k := range []struct {
+		key string
+		set bool
+	}{
+		{key: "requires", set: len(t.Requires) > 0},
+		{key: "conflicts", set: len(t.Conflicts) > 0},
+		{key: "oneof", set: t.OneOf != ""},
+		{key: "min", set: t.Min != ""},
+		{key: "max", set: t.Max != ""},
+		{key: "minlen", set: t.MinLen != ""},
+		{key: "maxlen", set: t.MaxLen != ""},
+		{key: "pattern", set: t.Pattern != ""},
+		{key: "nonempty", set: t.NonEmpty},
+		{key: "enum", set: len(t.Enum) > 0},
+	} {
+		if k.set {
+			keys = append(keys, k.key)
+		}
+	}
+	return keys
+}
+
+// genField writes registration of the field with flagarize struct tag. It mirrors parseField of the flagarize package.
+func (g *generator) genField(w io.Writer, reg string, field reflect.StructField, t types.Type, expr string, pos flagarize.FieldPos, tag *flagarize.Tag, sc *genScope) error {
+	if field.PkgPath != "" {
+		return &flagarize.PrivateFieldError{}
+	}
+	if keys := runtimeOnlyKeys(tag); len(keys) > 0 && !g.check {
+		return &flagarize.TagError{Key: keys[0], Reason: fmt.Sprintf("keys %v are not supported by generated code for field %q; use Flagarize instead", keys, field.Name)}
+	}
+	if !tag.Arg && sc.flags[tag.Name] {
+		return &flagarize.DuplicateFlagError{Flag: tag.Name}
+	}
+	if tag.Deprecated != "" {
+		tag.Help = strings.TrimSpace(fmt.Sprintf("%s (deprecated: %s)", tag.Help, tag.Deprecated))
+	}
+
+	value, cumulative, err := g.genValue(w, t, expr, tag)
+	if err != nil {
+		return err
+	}
+	if tag.Arg {
+		return g.genArg(w, reg, tag, value, cumulative, sc)
+	}
+
+	if value == "" {
+		// Custom Flagarizer.
+		ptr := "&" + expr
+		if _, ok := t.(*types.Pointer); ok {
+			ptr = expr
+		}
+		fmt.Fprintf(w, "if err := %s.Flagarize(%s, %s, unsafe.Pointer(%s)); err != nil {\n", expr, reg, g.tagLiteral(tag), ptr)
+		fmt.Fprintf(w, "return fmt.Errorf(%q, err)\n}\n", pos.String()+": custom Flagarizer: %w")
+		g.imports["fmt"], g.imports["unsafe"] = "fmt", "unsafe"
+		return nil
+	}
+	sc.flags[tag.Name] = true
+
+	fmt.Fprintf(w, "%s.Flag(%q, %q)", reg, tag.Name, tag.Help)
+	if tag.Short != 0 {
+		fmt.Fprintf(w, ".Short(%s)", strconv.QuoteRune(tag.Short))
+	}
+	if tag.Hidden {
+		fmt.Fprint(w, ".Hidden()")
+	}
+	if tag.Required {
+		fmt.Fprint(w, ".Required()")
+	}
+	if tag.DefaultValue != "" {
+		fmt.Fprintf(w, ".Default(%q)", tag.DefaultValue)
+	}
+	if tag.EnvName != "" {
+		fmt.Fprintf(w, ".Envar(%q)", tag.EnvName)
+	}
+	if tag.PlaceHolder != "" {
+		fmt.Fprintf(w, ".PlaceHolder(%q)", tag.PlaceHolder)
+	}
+	fmt.Fprintf(w, ".%s\n", value)
+	return nil
+}
+
+// genArg writes registration of the positional argument. It mirrors parseArg of the flagarize package.
+func (g *generator) genArg(w io.Writer, reg string, tag *flagarize.Tag, value string, cumulative bool, sc *genScope) error {
+	if value == "" {
+		return errors.New("custom Flagarizer is not supported for positional arguments; implement ValueFlagarizer instead")
+	}
+	for _, a := range sc.args {
+		if a.name == tag.Name {
+			return errors.Errorf("positional argument %q was already registered", tag.Name)
+		}
+		if a.cumulative {
+			return errors.Errorf("positional argument %q cannot follow variadic argument %q; variadic argument has to be the last one", tag.Name, a.name)
+		}
+		if tag.Required && !a.required {
+			return errors.Errorf("required positional argument %q cannot follow optional argument %q", tag.Name, a.name)
+		}
+	}
+	sc.args = append(sc.args, genArg{name: tag.Name, required: tag.Required, cumulative: cumulative})
+
+	fmt.Fprintf(w, "%s.Arg(%q, %q)", reg, tag.Name, tag.Help)
+	if tag.Required {
+		fmt.Fprint(w, ".Required()")
+	}
+	if tag.DefaultValue != "" {
+		fmt.Fprintf(w, ".Default(%q)", tag.DefaultValue)
+	}
+	if tag.EnvName != "" {
+		fmt.Fprintf(w, ".Envar(%q)", tag.EnvName)
+	}
+	fmt.Fprintf(w, ".%s\n", value)
+	return nil
+}
+
+// genValue returns the clause method call setting value of the field available under expr (e.g "StringVar(&c.F)")
+// and writes statements needed before it. Empty value is returned for custom Flagarizers. It mirrors
+// invokeFlagarizersIfImplements and registerBuiltinValue of the flagarize package.
+func (g *generator) genValue(w io.Writer, t types.Type, expr string, tag *flagarize.Tag) (value string, cumulative bool, err error) {
+	if g.isValue(t) {
+		// Only checked, so value is never written. Custom value has precedence over Flagarizer and ValueFlagarizer.
+		return "SetValue(nil)", false, nil
+	}
+
+	elem, isPtr := t, false
+	if p, ok := t.(*types.Pointer); ok {
+		elem, isPtr = p.Elem(), true
+	}
+
+	if g.implements(t, g.flagarizer) {
+		if g.hasValueMethod(elem, "Flagarize") {
+			return "", false, errors.New("custom Flagarizer is non receiver pointer")
+		}
+		if isPtr {
+			fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", expr, expr, g.typeString(elem))
+		}
+		return "", false, nil
+	}
+
+	if g.implements(t, g.valueFlagarizer) {
+		if g.hasValueMethod(elem, "Set") {
+			return "", false, errors.New("custom ValueFlagarizer is non receiver pointer")
+		}
+		ptr := "&" + expr
+		if isPtr {
+			fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", expr, expr, g.typeString(elem))
+			ptr = expr
+		}
+		return fmt.Sprintf("SetValue(flagarize.NewValue(%s, %q))", ptr, tag.DefaultValue), g.hasMethod(elem, "IsCumulative"), nil
+	}
+
+	typ := types.TypeString(t, func(p *types.Package) string { return p.Path() })
+	method, ok := builtinVarMethods[typ]
+	if !ok {
+		// UnsupportedTypeError requires reflect.Type, so only its message is the same.
+		return "", false, errors.Errorf("flagarize struct Tag found on not supported type %s", types.TypeString(t, types.RelativeTo(g.pkg)))
+	}
+	if method == "StringMapVar" {
+		fmt.Fprintf(w, "if %s == nil {\n%s = map[string]string{}\n}\n", expr, expr)
+	}
+	return fmt.Sprintf("%s(&%s)", method, expr), strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map["), nil
+}
+
+// tagLiteral returns Go expression of the tag passed to custom Flagarizer.
+func (g *generator) tagLiteral(t *flagarize.Tag) string {
+	var fields []string
+	add := func(name string, set bool, v string) {
+		if set {
+			fields = append(fields, name+": "+v)
+		}
+	}
+	add("Name", t.Name != "", strconv.Quote(t.Name))
+	add("Short", t.Short != 0, strconv.QuoteRune(t.Short))
+	add("EnvName", t.EnvName != "", strconv.Quote(t.EnvName))
+	add("Help", t.Help != "", strconv.Quote(t.Help))
+	add("DefaultValue", t.DefaultValue != "", strconv.Quote(t.DefaultValue))
+	add("PlaceHolder", t.PlaceHolder != "", strconv.Quote(t.PlaceHolder))
+	add("Hidden", t.Hidden, "true")
+	add("Required", t.Required, "true")
+	add("Group", t.Group != "", strconv.Quote(t.Group))
+	add("Secret", t.Secret, "true")
+	add("Key", t.Key != "", strconv.Quote(t.Key))
+	add("Deprecated", t.Deprecated != "", strconv.Quote(t.Deprecated))
+	return "&flagarize.Tag{" + strings.Join(fields, ", ") + "}"
+}
+
+// typeString returns type as written in the generated package, adding needed imports.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p.Path() == g.pkg.Path() {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// isValue returns true if type is registered with the flagarize.Value option (see Check).
+func (g *generator) isValue(t types.Type) bool { return g.values != nil && g.values(t) }
+
+// implements returns true if type or pointer to it implements the interface, the same as implementsFlagarizer of
+// the flagarize package.
+func (g *generator) implements(t types.Type, iface *types.Interface) bool {
+	if iface == nil {
+		return false
+	}
+	if types.Implements(t, iface) {
+		return true
+	}
+	_, isPtr := t.(*types.Pointer)
+	return !isPtr && types.Implements(types.NewPointer(t), iface)
+}
+
+// hasMethod returns true if type or pointer to it has the method.
+func (g *generator) hasMethod(t types.Type, name string) bool {
+	if _, isPtr := t.(*types.Pointer); !isPtr {
+		t = types.NewPointer(t)
+	}
+	return types.NewMethodSet(t).Lookup(g.pkg, name) != nil
+}
+
+// hasValueMethod returns true if the method is declared with value receiver, so it's in the method set of the type.
+func (g *generator) hasValueMethod(t types.Type, name string) bool {
+	return types.NewMethodSet(t).Lookup(g.pkg, name) != nil
+}
+
+// location returns location of the field the same way as Flagarize does.
+func (g *generator) location(t types.Type, field string) string {
+	if n, ok := t.(*types.Named); ok {
+		return n.Obj().Pkg().Path() + "." + n.Obj().Name() + "." + field
+	}
+	return t.String() + "." + field
+}
+
+func (g *generator) position(p token.Pos) string {
+	pos := g.fset.Position(p)
+	pos.Filename = filepath.Base(pos.Filename)
+	return pos.String()
+}
+
+// withFieldPos returns error with the given field position. Errors not carrying position are wrapped in FieldError,
+// the same as Flagarize does.
+func withFieldPos(err error, pos flagarize.FieldPos) error {
+	switch e := err.(type) {
+	case *flagarize.TagError:
+		e.FieldPos = pos
+	case *flagarize.PrivateFieldError:
+		e.FieldPos = pos
+	case *flagarize.DuplicateFlagError:
+		e.FieldPos = pos
+	default:
+		return &flagarize.FieldError{FieldPos: pos, Err: err}
+	}
+	return err
+}
+
+func hasHelpVar(st *types.Struct, field string) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() != field+"FlagarizeHelp" {
+			continue
+		}
+		b, ok := f.Type().Underlying().(*types.Basic)
+		return ok && b.Kind() == types.String
+	}
+	return false
+}
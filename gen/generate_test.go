@@ -0,0 +1,89 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package gen_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/gen"
+	"github.com/bwplotka/flagarize/internal/generatetest"
+	"github.com/bwplotka/flagarize/testutil"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("up to date", func(t *testing.T) {
+		var b bytes.Buffer
+		testutil.Ok(t, gen.Generate(&b, "../internal/generatetest", "Config"))
+
+		exp, err := ioutil.ReadFile(filepath.Join("..", "internal", "generatetest", "config_flagarize.go"))
+		testutil.Ok(t, err)
+		testutil.Equals(t, string(exp), b.String())
+	})
+	t.Run("equivalent to Flagarize", func(t *testing.T) {
+		runtimeApp, runtimeCfg := kingpin.New("app", ""), &generatetest.Config{}
+		testutil.Ok(t, flagarize.Flagarize(runtimeApp, runtimeCfg))
+		generatedApp, generatedCfg := kingpin.New("app", ""), &generatetest.Config{}
+		testutil.Ok(t, generatetest.FlagarizeConfig(generatedApp, generatedCfg))
+
+		args := []string{"-l", ":8080", "--url=http://localhost", "--label=a=b", "--since=2h", "--until=1h", "--rules=rule", "compact", "--dry-run", "dir", "b1", "b2"}
+		_, err := runtimeApp.Parse(args)
+		testutil.Ok(t, err)
+		_, err = generatedApp.Parse(args)
+		testutil.Ok(t, err)
+		help := generateTestHelp(t, flagarize.Flagarize)
+		testutil.Assert(t, strings.Contains(help, "--dry-run"), "unexpected help:\n%s", help)
+		testutil.Equals(t, help, generateTestHelp(t, func(r flagarize.KingpinRegistry, s interface{}, _ ...flagarize.OptFunc) error {
+			return generatetest.FlagarizeConfig(r.(*kingpin.Application), s.(*generatetest.Config))
+		}))
+
+		testutil.Equals(t, runtimeCfg.Web, generatedCfg.Web)
+		testutil.Equals(t, runtimeCfg.URL, generatedCfg.URL)
+		testutil.Equals(t, runtimeCfg.Labels, generatedCfg.Labels)
+		testutil.Equals(t, runtimeCfg.Since.String(), generatedCfg.Since.String())
+		testutil.Equals(t, runtimeCfg.Until.String(), generatedCfg.Until.String())
+		testutil.Equals(t, runtimeCfg.Compact, generatedCfg.Compact)
+
+		rc, err := runtimeCfg.Rules.Content()
+		testutil.Ok(t, err)
+		gc, err := generatedCfg.Rules.Content()
+		testutil.Ok(t, err)
+		testutil.Equals(t, string(rc), string(gc))
+	})
+	t.Run("tag errors", func(t *testing.T) {
+		err := gen.Generate(&bytes.Buffer{}, "testdata", "Config")
+		testutil.NotOk(t, err)
+		for _, exp := range []string{
+			"bad.go:4:2: Config.Nested.Retries: keys [max] are not supported by generated code",
+			`bad.go:10:2: Config.Typo: expected map-like Tag elements (e.g hidden=true) separated with |, found but no supported key found "defualt"`,
+			`did you mean "default"?`,
+			"bad.go:9:2: Config.Other: flag --listen was already registered",
+			`bad.go:12:2: Config.NoHelp: no help=<help> in struct Tag for field "NoHelp"`,
+			"bad.go:13:2: Config.private: flagarize struct Tag found on private field",
+		} {
+			testutil.Assert(t, strings.Contains(err.Error(), exp), "expected %q in:\n%s", exp, err)
+		}
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		testutil.NotOk(t, gen.Generate(&bytes.Buffer{}, "../internal/generatetest", "NotExisting"))
+	})
+}
+
+func generateTestHelp(t *testing.T, flagarizeFn func(flagarize.KingpinRegistry, interface{}, ...flagarize.OptFunc) error) string {
+	t.Helper()
+
+	app := kingpin.New("app", "")
+	b := bytes.Buffer{}
+	app.UsageWriter(&b)
+	app.Terminate(func(int) {})
+	testutil.Ok(t, flagarizeFn(app, &generatetest.Config{}))
+	_, _ = app.Parse([]string{"--help"})
+	_, _ = app.Parse([]string{"help", "compact"})
+	return b.String()
+}
@@ -0,0 +1,14 @@
+package bad
+
+type nested struct {
+	Retries int `flagarize:"name=retries|help=Retries.|max=10"`
+}
+
+type Config struct {
+	Listen  string `flagarize:"name=listen|help=Listen."`
+	Other   string `flagarize:"name=listen|help=Other listen."`
+	Typo    string `flagarize:"name=typo|help=Typo.|defualt=:80"`
+	Nested  nested `flagarize:"prefix=nested."`
+	NoHelp  string `flagarize:"name=no-help"`
+	private string `flagarize:"name=private|help=Private."`
+}
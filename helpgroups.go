@@ -0,0 +1,241 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const helpGroupFlagName = "help-group"
+
+// defaultUsageFlagsSection is the part of kingpin.DefaultUsageTemplate that renders flags. It's replaced with
+// pre-rendered flags in groups.
+const defaultUsageFlagsSection = `{{if .Context.Flags}}\
+Flags:
+{{.Context.Flags|FlagsToTwoColumns|FormatTwoColumns}}
+{{end}}\
+`
+
+// helpGroups holds help groups of flags registered in the kingpin application. It renders flags in --help
+// under group headings. It is also kingpin.Value of the --help-group flag.
+type helpGroups struct {
+	app       *kingpin.Application
+	collapsed bool
+
+	// order is the order of groups as they were declared.
+	order     []string
+	flagGroup map[string]string
+	selected  string
+}
+
+// installHelpGroups installs grouped help in the application, unless it was already installed.
+func installHelpGroups(r KingpinRegistry, collapsed bool) (*helpGroups, error) {
+	app, ok := r.(*kingpin.Application)
+	if !ok {
+		return nil, errors.Errorf("grouped help requires *kingpin.Application, got %T", r)
+	}
+	if f := app.GetFlag(helpGroupFlagName); f != nil {
+		h, ok := f.Model().Value.(*helpGroups)
+		if !ok {
+			return nil, errors.Errorf("grouped help requires --%s flag, but it was already registered", helpGroupFlagName)
+		}
+		h.collapsed = h.collapsed || collapsed
+		return h, nil
+	}
+
+	h := &helpGroups{app: app, collapsed: collapsed, flagGroup: map[string]string{}}
+	app.Flag(helpGroupFlagName, "Show context-sensitive help only for flags from the given group.").
+		PlaceHolder("<group>").PreAction(h.showGroup).SetValue(h)
+	app.HelpFlag.PreAction(func(ctx *kingpin.ParseContext) error {
+		h.setUsageTemplate(ctx)
+		return nil
+	})
+	return h, nil
+}
+
+// add adds flags to the given group. It's noop for nil helpGroups or empty group.
+func (h *helpGroups) add(group string, flags ...*kingpin.FlagClause) {
+	if h == nil || group == "" {
+		return
+	}
+	if !h.has(group) {
+		h.order = append(h.order, group)
+	}
+	for _, f := range flags {
+		h.flagGroup[f.Model().Name] = group
+	}
+}
+
+func (h *helpGroups) has(group string) bool {
+	for _, g := range h.order {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Set implements kingpin.Value.
+func (h *helpGroups) Set(group string) error {
+	if !h.has(group) {
+		return errors.Errorf("no help group %q; available groups: %s", group, strings.Join(h.order, ", "))
+	}
+	h.selected = group
+	return nil
+}
+
+// String implements kingpin.Value.
+func (h *helpGroups) String() string { return h.selected }
+
+// showGroup shows help for selected group and terminates, the same as --help does.
+func (h *helpGroups) showGroup(ctx *kingpin.ParseContext) error {
+	h.setUsageTemplate(ctx)
+	ctx.Elements = append(ctx.Elements, &kingpin.ParseElement{Clause: h.app.HelpFlag})
+	return nil
+}
+
+func (h *helpGroups) setUsageTemplate(ctx *kingpin.ParseContext) {
+	flags := h.app.Model().Flags
+	for _, e := range ctx.Elements {
+		if cmd, ok := e.Clause.(*kingpin.CmdClause); ok {
+			flags = append(flags, cmd.Model().Flags...)
+		}
+	}
+
+	b := &bytes.Buffer{}
+	h.writeFlags(b, flags)
+	h.app.UsageTemplate(strings.Replace(kingpin.DefaultUsageTemplate, defaultUsageFlagsSection, fmt.Sprintf("{{%q}}\\\n", b.String()), 1))
+}
+
+// shown returns true if flags of the given group are rendered in help.
+func (h *helpGroups) shown(group string) bool {
+	if h.selected != "" {
+		return group == h.selected
+	}
+	return group == "" || !h.collapsed
+}
+
+func (h *helpGroups) writeFlags(w io.Writer, flags []*kingpin.FlagModel) {
+	haveShort := false
+	for _, f := range flags {
+		if f.Short != 0 && !f.Hidden && h.shown(h.flagGroup[f.Name]) {
+			haveShort = true
+			break
+		}
+	}
+
+	sections := map[string][][2]string{}
+	for _, f := range flags {
+		if f.Hidden {
+			continue
+		}
+		g := h.flagGroup[f.Name]
+		sections[g] = append(sections[g], [2]string{formatFlag(haveShort, f), f.Help})
+	}
+
+	if h.selected != "" {
+		writeSections(w, [][2]string{{fmt.Sprintf("%s flags:", h.selected), ""}}, [][][2]string{sections[h.selected]})
+		return
+	}
+
+	headings := [][2]string{{"Flags:", ""}}
+	rows := [][][2]string{sections[""]}
+	if h.collapsed {
+		var groupRows [][2]string
+		for _, g := range h.order {
+			if len(sections[g]) > 0 {
+				groupRows = append(groupRows, [2]string{g, fmt.Sprintf("%d flag(s)", len(sections[g]))})
+			}
+		}
+		headings = append(headings, [2]string{"Flag groups:", fmt.Sprintf("(use --%s=<group> to show flags of the group)", helpGroupFlagName)})
+		rows = append(rows, groupRows)
+		writeSections(w, headings, rows)
+		return
+	}
+
+	for _, g := range h.order {
+		headings = append(headings, [2]string{fmt.Sprintf("%s flags:", g), ""})
+		rows = append(rows, sections[g])
+	}
+	writeSections(w, headings, rows)
+}
+
+// writeSections writes non empty sections of rows in two columns under the headings. The first column has the same
+// width in all sections.
+func writeSections(w io.Writer, headings [][2]string, sections [][][2]string) {
+	s := 0
+	for _, rows := range sections {
+		for _, row := range rows {
+			if c := len(row[0]); c > s && c < 30 {
+				s = c
+			}
+		}
+	}
+	for i, rows := range sections {
+		if len(rows) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, strings.TrimSpace(strings.Join(headings[i][:], " ")))
+		formatTwoColumns(w, s, 2, 2, guessWidth(), rows)
+		fmt.Fprintln(w)
+	}
+}
+
+// formatTwoColumns is formatting the same as kingpin does, but with the given size of the first column.
+func formatTwoColumns(w io.Writer, s, indent, padding, width int, rows [][2]string) {
+	indentStr := strings.Repeat(" ", indent)
+	offsetStr := strings.Repeat(" ", s+padding)
+
+	for _, row := range rows {
+		buf := bytes.NewBuffer(nil)
+		doc.ToText(buf, row[1], "", "  ", width-s-padding-indent)
+		lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		fmt.Fprintf(w, "%s%-*s%*s", indentStr, s, row[0], padding, "")
+		if len(row[0]) >= 30 {
+			fmt.Fprintf(w, "\n%s%s", indentStr, offsetStr)
+		}
+		fmt.Fprintf(w, "%s\n", lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "%s%s%s\n", indentStr, offsetStr, line)
+		}
+	}
+}
+
+// formatFlag formats flag the same as kingpin does.
+func formatFlag(haveShort bool, flag *kingpin.FlagModel) string {
+	flagString := ""
+	if flag.Short != 0 {
+		flagString += fmt.Sprintf("-%c, --%s", flag.Short, flag.Name)
+	} else {
+		if haveShort {
+			flagString += fmt.Sprintf("    --%s", flag.Name)
+		} else {
+			flagString += fmt.Sprintf("--%s", flag.Name)
+		}
+	}
+	if !flag.IsBoolFlag() {
+		flagString += fmt.Sprintf("=%s", flag.FormatPlaceHolder())
+	}
+	if v, ok := flag.Value.(interface{ IsCumulative() bool }); ok && v.IsCumulative() {
+		flagString += " ..."
+	}
+	return flagString
+}
+
+// guessWidth returns width of the terminal from COLUMNS environment variable or 80.
+func guessWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+		return cols
+	}
+	return 80
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+func TestFlagarize_GroupedHelp(t *testing.T) {
+	type webConfig struct {
+		Address string `flagarize:"name=address|help=Address to listen on."`
+		Timeout int    `flagarize:"name=timeout|help=Timeout.|short=t"`
+		Secret  string `flagarize:"name=secret|help=Secret.|hidden=true"`
+	}
+	type config struct {
+		Debug bool      `flagarize:"name=debug|help=Debug."`
+		Web   webConfig `flagarize:"prefix=web.|group=web"`
+		Path  string    `flagarize:"name=path|help=Storage path.|group=storage"`
+		Other string    `flagarize:"name=other|help=Other."`
+	}
+
+	for _, tcase := range []struct {
+		opt           flagarize.OptFunc
+		input         []string
+		expectedUsage string
+		expectedErr   string
+	}{
+		{
+			opt:   flagarize.WithGroupedHelp(),
+			input: []string{"--help"},
+			expectedUsage: `usage: test [<flags>]
+
+test
+
+Flags:
+      --help                     Show context-sensitive help (also try
+                                 --help-long and --help-man).
+      --help-group=<group>       Show context-sensitive help only for flags from
+                                 the given group.
+      --debug                    Debug.
+      --other=OTHER              Other.
+
+web flags:
+      --web.address=WEB.ADDRESS  Address to listen on.
+  -t, --web.timeout=WEB.TIMEOUT  Timeout.
+
+storage flags:
+      --path=PATH                Storage path.
+
+`,
+		},
+		{
+			opt:   flagarize.WithCollapsedGroupedHelp(),
+			input: []string{"--help"},
+			expectedUsage: `usage: test [<flags>]
+
+test
+
+Flags:
+  --help                Show context-sensitive help (also try --help-long and
+                        --help-man).
+  --help-group=<group>  Show context-sensitive help only for flags from the
+                        given group.
+  --debug               Debug.
+  --other=OTHER         Other.
+
+Flag groups: (use --help-group=<group> to show flags of the group)
+  web                   2 flag(s)
+  storage               1 flag(s)
+
+`,
+		},
+		{
+			opt:   flagarize.WithCollapsedGroupedHelp(),
+			input: []string{"--help-group=storage"},
+			expectedUsage: `usage: test [<flags>]
+
+test
+
+storage flags:
+  --path=PATH  Storage path.
+
+`,
+		},
+		{
+			opt:         flagarize.WithGroupedHelp(),
+			input:       []string{"--help-group=nope"},
+			expectedErr: "no help group \"nope\"; available groups: web, storage",
+		},
+	} {
+		t.Run(fmt.Sprintf("%v", tcase.input), func(t *testing.T) {
+			app := newTestKingpin(t)
+			b := bytes.Buffer{}
+			app.UsageWriter(&b)
+
+			var terminates bool
+			app.Terminate(func(int) { terminates = true })
+
+			testutil.Ok(t, flagarize.Flagarize(app, &config{}, tcase.opt))
+			_, err := app.Parse(tcase.input)
+			if tcase.expectedErr != "" {
+				testutil.NotOk(t, err)
+				testutil.Equals(t, tcase.expectedErr, err.Error())
+				return
+			}
+			testutil.Ok(t, err)
+			testutil.Assert(t, terminates, "parse did not terminate")
+			testutil.Equals(t, tcase.expectedUsage, b.String())
+		})
+	}
+
+	t.Run("not an application", func(t *testing.T) {
+		err := flagarize.Flagarize(newTestKingpin(t).Command("cmd", "cmd"), &config{}, flagarize.WithGroupedHelp())
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: grouped help requires *kingpin.Application, got *kingpin.CmdClause", err.Error())
+	})
+}
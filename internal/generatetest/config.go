@@ -0,0 +1,39 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package generatetest contains config with code generated by flagarize-gen, used to test that generated code is
+// equivalent to Flagarize for the subset of struct tag keys flagarize-gen supports.
+package generatetest
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+)
+
+//go:generate go run github.com/bwplotka/flagarize/cmd/flagarize-gen --type=Config
+
+type WebConfig struct {
+	Listen  string        `flagarize:"name=listen|help=Listen address.|default=:80|envvar=LISTEN|short=l"`
+	Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|hidden=true"`
+}
+
+type CompactConfig struct {
+	DryRun bool     `flagarize:"name=dry-run|help=Dry run."`
+	Dir    string   `flagarize:"arg=dir|help=Directory.|required=true"`
+	Blocks []string `flagarize:"arg=blocks|help=Blocks to compact."`
+}
+
+type Config struct {
+	Web     WebConfig                 `flagarize:"prefix=web.|envprefix=WEB_|group=web"`
+	URL     *url.URL                  `flagarize:"name=url|help=Remote URL.|placeholder=<url>"`
+	Labels  map[string]string         `flagarize:"name=label|help=Labels."`
+	Since   flagarize.TimeOrDuration  `flagarize:"name=since|help=Since.|default=1h"`
+	Until   *flagarize.TimeOrDuration `flagarize:"name=until|help=Until."`
+	Rules   flagarize.PathOrContent   `flagarize:"name=rules|help=rules.|deprecated=use --rules-file instead"`
+	Token   string                    `flagarize:"name=token|help=Token.|secret=true"`
+	Compact CompactConfig             `flagarize:"cmd=compact|help=Compact blocks."`
+	Ignored string                    `flagarize:"-"`
+	NotFlag int
+}
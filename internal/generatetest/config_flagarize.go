@@ -0,0 +1,36 @@
+// Code generated by flagarize-gen. DO NOT EDIT.
+
+package generatetest
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/bwplotka/flagarize"
+)
+
+// FlagarizeConfig registers flags of Config the same as flagarize.Flagarize(r, c) without options. Only the subset of
+// struct tag keys without checks after parse is supported.
+func FlagarizeConfig(r flagarize.Registry, c *Config) error {
+	r.Flag("web.listen", "Listen address.").Short('l').Default(":80").Envar("WEB_LISTEN").StringVar(&c.Web.Listen)
+	r.Flag("web.timeout", "Timeout.").Hidden().Default("1m").DurationVar(&c.Web.Timeout)
+	r.Flag("url", "Remote URL.").PlaceHolder("<url>").URLVar(&c.URL)
+	if c.Labels == nil {
+		c.Labels = map[string]string{}
+	}
+	r.Flag("label", "Labels.").StringMapVar(&c.Labels)
+	r.Flag("since", "Since.").Default("1h").SetValue(flagarize.NewValue(&c.Since, "1h"))
+	if c.Until == nil {
+		c.Until = new(flagarize.TimeOrDuration)
+	}
+	r.Flag("until", "Until.").SetValue(flagarize.NewValue(c.Until, ""))
+	if err := c.Rules.Flagarize(r, &flagarize.Tag{Name: "rules", Help: "rules. (deprecated: use --rules-file instead)", Deprecated: "use --rules-file instead"}, unsafe.Pointer(&c.Rules)); err != nil {
+		return fmt.Errorf("Config.Rules: custom Flagarizer: %w", err)
+	}
+	r.Flag("token", "Token.").StringVar(&c.Token)
+	cmd1 := r.Command("compact", "Compact blocks.")
+	cmd1.Flag("dry-run", "Dry run.").BoolVar(&c.Compact.DryRun)
+	cmd1.Arg("dir", "Directory.").Required().StringVar(&c.Compact.Dir)
+	cmd1.Arg("blocks", "Blocks to compact.").StringsVar(&c.Compact.Blocks)
+	return nil
+}
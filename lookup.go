@@ -0,0 +1,175 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Source is a custom source of flag values e.g secrets mount or key-value store. See WithSources.
+type Source interface {
+	// Lookup returns value of the flag described by the given spec. It returns false if the source has no value for
+	// the flag. Values of repeatable flags (slices and maps) are separated by new lines, the same as for environment
+	// variables.
+	Lookup(spec FlagSpec) (string, bool, error)
+}
+
+// WithSources makes values of flags not set in the command line nor environment variable (including env file) to be
+// looked up in the given sources, in the given order. The first source that has the value wins. Sources have priority
+// over the config file (see WithConfigFile) and defaults. Sources are consulted on each parse, for top level flags and
+// flags of the selected command only. Positional arguments are not looked up.
+//
+// If Source implements fmt.Stringer, it is used to describe the source in errors.
+// It requires KingpinRegistry to be *kingpin.Application or *kingpin.CmdClause.
+func WithSources(s ...Source) OptFunc {
+	return func(opt *opts) { opt.sources = append(opt.sources, s...) }
+}
+
+// lookupSources looks up values of flags in custom sources.
+type lookupSources struct {
+	sources []Source
+	flags   []*lookupFlag
+	// elements are parse elements added for values from sources on the last parse.
+	elements sourceElements
+}
+
+type lookupFlag struct {
+	fileField
+
+	spec FlagSpec
+	// cmd is the command flag is registered in, nil for top level flags.
+	cmd *kingpin.CmdClause
+}
+
+// installSources registers pre-parse action looking up values of flags in the given sources.
+func installSources(r KingpinRegistry, sources []Source) (*lookupSources, error) {
+	l := &lookupSources{sources: sources, elements: sourceElements{}}
+	if err := addPreAction(r, l.apply); err != nil {
+		return nil, errors.Wrap(err, "sources")
+	}
+	return l, nil
+}
+
+// add adds flag, so its value can be looked up in sources. It's noop for nil lookupSources.
+func (l *lookupSources) add(spec FlagSpec, f *kingpin.FlagClause, fieldValue reflect.Value, cmd *kingpin.CmdClause) {
+	if l == nil {
+		return
+	}
+	l.flags = append(l.flags, &lookupFlag{fileField: fileField{flag: f, value: fieldValue}, spec: spec, cmd: cmd})
+}
+
+// apply sets values from sources for flags that were not set by flag nor environment variable. It runs before required
+// flags are checked, so sources can provide values of required flags.
+func (l *lookupSources) apply(ctx *kingpin.ParseContext) error {
+	l.elements.reset()
+
+	var merr MultiError
+	for _, lf := range l.flags {
+		if lf.cmd != nil && !isCommandSelected(ctx, lf.cmd) {
+			continue
+		}
+		if isFlagSet(ctx, lf.flag) {
+			continue
+		}
+		for _, s := range l.sources {
+			v, ok, err := s.Lookup(lf.spec)
+			if err != nil {
+				merr.Append(errors.Wrapf(err, "lookup flag --%s in source %s", lf.spec.Name, sourceName(s)))
+				break
+			}
+			if !ok {
+				continue
+			}
+			if err := l.set(ctx, lf, v, sourceName(s)); err != nil {
+				merr.Append(err)
+			}
+			break
+		}
+	}
+	return merr.Err()
+}
+
+func (l *lookupSources) set(ctx *kingpin.ParseContext, lf *lookupFlag, v, name string) error {
+	values := []string{v}
+	if isCumulative(lf.flag.Model().Value) {
+		values = strings.Split(strings.TrimSuffix(strings.Replace(v, "\r\n", "\n", -1), "\n"), "\n")
+	}
+	if err := lf.setValues(values); err != nil {
+		return errors.Wrapf(err, "source %s: flag --%s", name, lf.spec.Name)
+	}
+	for _, s := range values {
+		l.elements.add(ctx, lf.flag, s, elementSource{source: SourceCustom, location: name})
+	}
+	return nil
+}
+
+// isCommandSelected returns true if the given command was selected in the parse context.
+func isCommandSelected(ctx *kingpin.ParseContext, cmd *kingpin.CmdClause) bool {
+	for _, e := range ctx.Elements {
+		if e.Clause == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+func sourceName(s Source) string {
+	if st, ok := s.(fmt.Stringer); ok {
+		return st.String()
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+// DirSource is a Source reading values from the directory with file per flag e.g Kubernetes secrets mount. The file
+// is named as the flag (e.g `web.timeout`) or, if it does not exist, as the environment variable of the flag. Single
+// trailing new line is trimmed from the content.
+type DirSource struct {
+	dir string
+}
+
+// NewDirSource returns DirSource reading values from the given directory.
+func NewDirSource(dir string) *DirSource { return &DirSource{dir: dir} }
+
+// Lookup implements Source.
+func (d *DirSource) Lookup(spec FlagSpec) (string, bool, error) {
+	names := []string{spec.Name}
+	if spec.EnvVar != "" {
+		names = append(names, spec.EnvVar)
+	}
+	for _, n := range names {
+		b, err := ioutil.ReadFile(filepath.Join(d.dir, n))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", false, err
+		}
+		s := strings.TrimSuffix(string(b), "\n")
+		return strings.TrimSuffix(s, "\r"), true, nil
+	}
+	return "", false, nil
+}
+
+// String implements fmt.Stringer.
+func (d *DirSource) String() string { return fmt.Sprintf("dir %s", d.dir) }
+
+// MapSource is an in-memory Source with values by flag name e.g for tests.
+type MapSource map[string]string
+
+// Lookup implements Source.
+func (m MapSource) Lookup(spec FlagSpec) (string, bool, error) {
+	v, ok := m[spec.Name]
+	return v, ok, nil
+}
+
+// String implements fmt.Stringer.
+func (m MapSource) String() string { return "map" }
@@ -0,0 +1,120 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+	"github.com/pkg/errors"
+)
+
+type lookupConfig struct {
+	Timeout  time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|envvar=LOOKUP_TIMEOUT"`
+	Password string        `flagarize:"name=password|help=Password.|required=true|envvar=LOOKUP_PASSWORD|secret=true"`
+	Peers    []string      `flagarize:"name=peer|help=Peers.|default=a"`
+	Retries  int           `flagarize:"name=retries|help=Retries.|max=10"`
+	Address  string        `flagarize:"name=address|help=Address.|default=:80"`
+}
+
+type failingSource struct{}
+
+func (failingSource) Lookup(flagarize.FlagSpec) (string, bool, error) {
+	return "", false, errors.New("unavailable")
+}
+
+func TestFlagarize_Sources(t *testing.T) {
+	t.Run("order and precedence", func(t *testing.T) {
+		testutil.Ok(t, os.Setenv("LOOKUP_TIMEOUT", "5m"))
+		defer func() { testutil.Ok(t, os.Unsetenv("LOOKUP_TIMEOUT")) }()
+
+		dir, err := ioutil.TempDir("", "flagarize")
+		testutil.Ok(t, err)
+		defer func() { testutil.Ok(t, os.RemoveAll(dir)) }()
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "LOOKUP_PASSWORD"), []byte("secret\n"), os.ModePerm))
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "retries"), []byte("3"), os.ModePerm))
+		c := writeConfigFile(t, `{"retries": 4, "peer": ["x"]}`)
+
+		app := newTestKingpin(t)
+		cfg := &lookupConfig{}
+		f, err := flagarize.New(app, cfg, flagarize.WithConfigFile(), flagarize.WithSources(
+			flagarize.NewDirSource(dir),
+			flagarize.MapSource{"timeout": "10m", "retries": "5", "peer": "b\nc", "password": "other"},
+		))
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"--config.file=" + c, "--address=:8080"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, &lookupConfig{
+			Timeout:  5 * time.Minute,
+			Password: "secret",
+			Peers:    []string{"b", "c"},
+			Retries:  3,
+			Address:  ":8080",
+		}, cfg)
+
+		sources, err := f.Sources()
+		testutil.Ok(t, err)
+		testutil.Equals(t, flagarize.SourceEnv, sources["Timeout"].Source)
+		testutil.Equals(t, flagarize.FieldSource{
+			Path: flagarize.FieldPath{"Retries"}, Flag: "retries",
+			SourceValue: flagarize.SourceValue{Source: flagarize.SourceCustom, Raw: []string{"3"}},
+			Overridden: []flagarize.SourceValue{
+				{Source: flagarize.SourceConfigFile, Raw: []string{"4"}},
+				{Source: flagarize.SourceDefault},
+			},
+		}, sources["Retries"])
+		testutil.Equals(t, flagarize.SourceFlag, sources["Address"].Source)
+	})
+	t.Run("violation reports source", func(t *testing.T) {
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &lookupConfig{}, flagarize.WithSources(flagarize.MapSource{"password": "a", "retries": "11"})))
+
+		_, err := app.Parse(nil)
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "invalid value \"11\" from source map (flag --retries): has to be less than or equal to 10", err.Error())
+	})
+	t.Run("lookup error", func(t *testing.T) {
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &lookupConfig{}, flagarize.WithSources(failingSource{})))
+
+		_, err := app.Parse([]string{"--password=a", "--timeout=1s", "--peer=a", "--retries=1"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "lookup flag --address in source flagarize_test.failingSource: unavailable", err.Error())
+	})
+	t.Run("flags of not selected commands are not looked up", func(t *testing.T) {
+		type cmdConfig struct {
+			Run struct {
+				Name string `flagarize:"name=name|help=Name.|required=true"`
+			} `flagarize:"cmd=run|help=Run."`
+			Other struct {
+				Name string `flagarize:"name=other.name|help=Name."`
+			} `flagarize:"cmd=other|help=Other."`
+		}
+		var looked []string
+		src := funcSource(func(spec flagarize.FlagSpec) (string, bool, error) {
+			looked = append(looked, spec.Command+" "+spec.Name)
+			return "x", true, nil
+		})
+
+		app := newTestKingpin(t)
+		cfg := &cmdConfig{}
+		testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithSources(src)))
+
+		_, err := app.Parse([]string{"run"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, "x", cfg.Run.Name)
+		testutil.Equals(t, "", cfg.Other.Name)
+		testutil.Equals(t, []string{"run name"}, looked)
+	})
+}
+
+type funcSource func(spec flagarize.FlagSpec) (string, bool, error)
+
+func (f funcSource) Lookup(spec flagarize.FlagSpec) (string, bool, error) { return f(spec) }
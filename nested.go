@@ -0,0 +1,149 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	prefixStructTagKey    = "prefix"
+	envprefixStructTagKey = "envprefix"
+	cmdStructTagKey       = "cmd"
+)
+
+var supportedNestedStructTagKeys = []string{prefixStructTagKey, envprefixStructTagKey, groupStructTagKey, hiddenStructTagKey, requiredStructTagKey, cmdStructTagKey}
+
+// nestedTag is parsed flagarize struct tag of the nested struct field. Its options are inherited by all flags
+// registered from fields of this struct (including structs nested in it), unless overridden by them.
+type nestedTag struct {
+	namePrefix string
+	envPrefix  string
+	group      string
+	hidden     bool
+
+	// cmd is the name of the command registered from this nested struct (not inherited) if any.
+	cmd     string
+	cmdHelp string
+
+	// requiredTogether are sets of flags, that all have to be set if any flag from the set was set.
+	requiredTogether []*requiredTogether
+	// ownRequiredTogether is a set of requiredTogether created by this nested struct tag (not inherited) if any.
+	ownRequiredTogether *requiredTogether
+}
+
+// parseNestedTag parses flagarize struct tag of the struct field. It returns false if field has struct tag
+// that is not nested struct tag (e.g. it has keys that are supported only by flags).
+func parseNestedTag(field reflect.StructField, parent *nestedTag, path FieldPath, elemSep string) (*nestedTag, bool, error) {
+	val, ok := field.Tag.Lookup(flagTagName)
+	if !ok {
+		return parent, true, nil
+	}
+
+	n := *parent
+	n.ownRequiredTogether = nil
+	n.cmd, n.cmdHelp = "", ""
+	if val == "" {
+		return &n, true, nil
+	}
+
+	elems := strings.Split(val, elemSep)
+	isCmd := false
+	for _, t := range elems {
+		isCmd = isCmd || strings.HasPrefix(t, cmdStructTagKey+"=")
+	}
+	isFlag := false
+	for _, t := range elems {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 {
+			return nil, false, nil
+		}
+		if !isNestedStructTagKey(kv[0]) && !isKey(kv[0], supportedStuctTagKeys) {
+			// Struct field can be either nested struct or flag, so keys of both are suggested.
+			return nil, true, &TagError{
+				Key:        kv[0],
+				Suggestion: suggestKey(kv[0], supportedNestedStructTagKeys, supportedStuctTagKeys),
+				Reason: fmt.Sprintf("expected map-like Tag elements (e.g prefix=web.) separated with %s, found but"+
+					" no supported key found %q for field %q; only %v are supported for nested structs and %v for flags",
+					elemSep, kv[0], field.Name, supportedNestedStructTagKeys, supportedStuctTagKeys),
+			}
+		}
+		// Help is supported only for commands, otherwise it's a flag tag.
+		isFlag = isFlag || (!isNestedStructTagKey(kv[0]) && (!isCmd || kv[0] != helpStructTagKey))
+	}
+	if isFlag {
+		return nil, false, nil
+	}
+
+	n.requiredTogether = append([]*requiredTogether(nil), parent.requiredTogether...)
+	for _, t := range elems {
+		kv := strings.SplitN(t, "=", 2)
+		switch kv[0] {
+		case prefixStructTagKey:
+			n.namePrefix += kv[1]
+		case envprefixStructTagKey:
+			if kv[1] != strings.ToUpper(kv[1]) {
+				return nil, true, &TagError{Key: kv[0], Reason: fmt.Sprintf("environment variable prefix has to be upper case, but it's not %q for field %q", kv[1], field.Name)}
+			}
+			n.envPrefix += kv[1]
+		case groupStructTagKey:
+			n.group = kv[1]
+		case hiddenStructTagKey:
+			n.hidden = isTrue(kv[1])
+		case requiredStructTagKey:
+			if !isTrue(kv[1]) {
+				n.requiredTogether = nil
+				continue
+			}
+			n.ownRequiredTogether = &requiredTogether{path: path}
+			n.requiredTogether = append(n.requiredTogether, n.ownRequiredTogether)
+		case cmdStructTagKey:
+			n.cmd = kv[1]
+		case helpStructTagKey:
+			n.cmdHelp = kv[1]
+		}
+	}
+	return &n, true, nil
+}
+
+func isNestedStructTagKey(key string) bool { return isKey(key, supportedNestedStructTagKeys) }
+
+func isKey(key string, keys []string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredTogether is a set of flags registered from the nested struct with required=true. If any of those
+// flags is set, all of them are required.
+type requiredTogether struct {
+	path  FieldPath
+	flags []*kingpin.FlagClause
+}
+
+func (r *requiredTogether) check(ctx *kingpin.ParseContext) error {
+	var set, notSet, all []string
+	for _, f := range r.flags {
+		name := "--" + f.Model().Name
+		all = append(all, name)
+		if isFlagSet(ctx, f) {
+			set = append(set, name)
+			continue
+		}
+		notSet = append(notSet, name)
+	}
+	if len(set) == 0 || len(notSet) == 0 {
+		return nil
+	}
+	return errors.Errorf("required flag(s) %s not provided; flags %s of %s have to be set together, but only %s was set",
+		strings.Join(notSet, ", "), strings.Join(all, ", "), r.path, strings.Join(set, ", "))
+}
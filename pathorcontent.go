@@ -45,7 +45,8 @@ func (p *PathOrContent) Flagarize(r FlagRegisterer, tag *Tag, _ unsafe.Pointer)
 	contentFlagName := tag.Name
 
 	fileHelp := fmt.Sprintf("Path to %s", tag.Help)
-	p.path = r.Flag(fileFlagName, fileHelp).PlaceHolder("<file-path>").String()
+	p.path = new(string)
+	r.Flag(fileFlagName, fileHelp).PlaceHolder("<file-path>").SetValue((*filePath)(p.path))
 
 	contentHelp := fmt.Sprintf("Alternative to '%s' flag (lower priority). Content of %s", fileFlagName, tag.Help)
 	p.content = r.Flag(contentFlagName, contentHelp).PlaceHolder("<content>").String()
@@ -88,3 +89,15 @@ func (p *PathOrContent) Content() ([]byte, error) {
 
 	return content, nil
 }
+
+// filePath is kingpin.Value of the flag with path to the file, so file names are completed for it (see WriteCompletion).
+type filePath string
+
+// Set implements kingpin.Value.
+func (p *filePath) Set(s string) error {
+	*p = filePath(s)
+	return nil
+}
+
+// String implements kingpin.Value.
+func (p *filePath) String() string { return string(*p) }
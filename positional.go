@@ -0,0 +1,138 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const argStructTagKey = "arg"
+
+// supportedArgStructTagKeys are struct tag keys that can be used together with arg.
+var supportedArgStructTagKeys = []string{argStructTagKey, helpStructTagKey, requiredStructTagKey, defaultStructTagKey, envvarStructTagKey, secretStructTagKey}
+
+// parseArgTag checks if the tag with arg=<name> has only keys supported by positional arguments.
+func parseArgTag(t *Tag, field reflect.StructField, keys []string) error {
+	if t.Name == "" {
+		return &TagError{Key: argStructTagKey, Reason: fmt.Sprintf("arg name cannot be empty for field %q", field.Name)}
+	}
+	for _, k := range keys {
+		supported := false
+		for _, s := range supportedArgStructTagKeys {
+			if k == s {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return &TagError{Key: k, Reason: fmt.Sprintf("key %q is not supported for positional argument %q of field %q; only %v are supported", k, t.Name, field.Name, supportedArgStructTagKeys)}
+		}
+	}
+	return nil
+}
+
+// argRegisterer allows registering positional arguments e.g *kingpin.Application or *kingpin.CmdClause.
+type argRegisterer interface {
+	Arg(name, help string) *kingpin.ArgClause
+}
+
+// registeredArgs returns models of positional arguments already registered in the registry.
+func registeredArgs(r KingpinRegistry) []*kingpin.ArgModel {
+	switch a := r.(type) {
+	case *kingpin.Application:
+		return a.Model().Args
+	case *kingpin.CmdClause:
+		return a.Model().Args
+	}
+	return nil
+}
+
+func isCumulative(v kingpin.Value) bool {
+	c, ok := v.(interface{ IsCumulative() bool })
+	return ok && c.IsCumulative()
+}
+
+// parseArg registers positional argument for the field with arg=<name> struct tag. Arguments are registered in the
+// declaration order. Value is created the same way as for flags, so the same types are supported.
+func parseArg(r KingpinRegistry, fieldValue reflect.Value, fieldPath FieldPath, pos FieldPos, tag *Tag, o opts) error {
+	ar, ok := r.(argRegisterer)
+	if !ok {
+		return withFieldPos(errors.Errorf("registry %T does not allow registering positional arguments", r), pos)
+	}
+	if implementsFlagarizer(fieldValue.Type()) && o.customValue(fieldValue.Type()) == nil {
+		return withFieldPos(errors.New("custom Flagarizer is not supported for positional arguments; implement ValueFlagarizer instead"), pos)
+	}
+
+	// Create value in the temporary application, so it's the same as for the flag.
+	tmp := kingpin.New(tag.Name, "")
+	ok, err := registerCustomValue(tmp, &Tag{Name: tag.Name, DefaultValue: tag.DefaultValue}, fieldValue, o)
+	if err != nil {
+		return withFieldPos(err, pos)
+	}
+	if !ok {
+		if err := registerBuiltinValue(tmp.Flag(tag.Name, ""), fieldValue); err != nil {
+			return withFieldPos(err, pos)
+		}
+	}
+	value := tmp.GetFlag(tag.Name).Model().Value
+
+	for _, a := range registeredArgs(r) {
+		if a.Name == tag.Name {
+			return withFieldPos(errors.Errorf("positional argument %q was already registered", tag.Name), pos)
+		}
+		if isCumulative(a.Value) {
+			return withFieldPos(errors.Errorf("positional argument %q cannot follow variadic argument %q; variadic argument has to be the last one", tag.Name, a.Name), pos)
+		}
+		if tag.Required && !a.Required {
+			return withFieldPos(errors.Errorf("required positional argument %q cannot follow optional argument %q", tag.Name, a.Name), pos)
+		}
+	}
+
+	c := ar.Arg(tag.Name, tag.Help)
+	if tag.Required {
+		c.Required()
+	}
+	if len(tag.defaultValues) > 0 {
+		c.Default(tag.defaultValues...)
+	} else if tag.DefaultValue != "" {
+		c.Default(tag.DefaultValue)
+	}
+	if tag.EnvName != "" {
+		c.Envar(tag.EnvName)
+	}
+	c.SetValue(value)
+
+	if o.specs != nil {
+		m := c.Model()
+		spec := FlagSpec{
+			Name:       m.Name,
+			EnvVar:     m.Envar,
+			Help:       m.Help,
+			Default:    m.Default,
+			Required:   m.Required,
+			Secret:     tag.Secret,
+			Deprecated: tag.Deprecated,
+			Arg:        true,
+			Path:       fieldPath,
+			Type:       fieldValue.Type().String(),
+		}
+		if o.cmd != nil {
+			spec.Command = o.cmd.FullCommand()
+		}
+		*o.specs = append(*o.specs, spec)
+	}
+	return nil
+}
+
+// flagOrArg returns name of the flag (e.g "--name") or positional argument (e.g "<name>") registered for the tag.
+func flagOrArg(t *Tag) string {
+	if t.Arg {
+		return fmt.Sprintf("<%s>", t.Name)
+	}
+	return "--" + t.Name
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type inspectArgsCmd struct {
+	Output  string        `flagarize:"name=output|help=Output."`
+	Bucket  string        `flagarize:"arg=bucket|help=Bucket.|required=true"`
+	Timeout time.Duration `flagarize:"arg=timeout|help=Timeout.|default=1m|envvar=INSPECT_TIMEOUT"`
+	Blocks  []string      `flagarize:"arg=block-dir|help=Block dirs."`
+}
+
+func (inspectArgsCmd) Run(context.Context) error { return nil }
+
+type argsConfig struct {
+	Inspect inspectArgsCmd `flagarize:"cmd=inspect|help=Inspect."`
+}
+
+func TestFlagarize_Args(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		app := newTestKingpin(t)
+		cfg := &argsConfig{}
+		f, err := flagarize.New(app, cfg)
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"inspect", "bkt", "--output=json", "5m", "a", "b"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, inspectArgsCmd{Output: "json", Bucket: "bkt", Timeout: 5 * time.Minute, Blocks: []string{"a", "b"}}, cfg.Inspect)
+
+		args, err := f.ToArgs()
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{"inspect", "--output=json", "bkt", "5m0s", "a", "b"}, args)
+	})
+	t.Run("defaults and envvars", func(t *testing.T) {
+		testutil.Ok(t, os.Setenv("INSPECT_TIMEOUT", "2m"))
+		defer func() { testutil.Ok(t, os.Unsetenv("INSPECT_TIMEOUT")) }()
+
+		app := newTestKingpin(t)
+		cfg := &argsConfig{}
+		f, err := flagarize.New(app, cfg)
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"inspect"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "required argument 'bucket' not provided", err.Error())
+
+		_, err = app.Parse([]string{"inspect", "-bkt"})
+		testutil.NotOk(t, err)
+		_, err = app.Parse([]string{"inspect", "--", "-bkt"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, inspectArgsCmd{Bucket: "-bkt", Timeout: 2 * time.Minute}, cfg.Inspect)
+
+		args, err := f.ToArgs()
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{"inspect", "--", "-bkt", "2m0s"}, args)
+	})
+	t.Run("describe", func(t *testing.T) {
+		specs, err := flagarize.Describe(&argsConfig{})
+		testutil.Ok(t, err)
+		testutil.Equals(t, flagarize.FlagSpec{
+			Name: "timeout", EnvVar: "INSPECT_TIMEOUT", Help: "Timeout.", Default: []string{"1m"}, Arg: true, Command: "inspect",
+			Path: flagarize.FieldPath{"Inspect", "Timeout"}, Type: "time.Duration",
+		}, specs[2])
+	})
+	t.Run("errors", func(t *testing.T) {
+		type config struct {
+			Files    []string `flagarize:"arg=file|help=Files."`
+			Name     string   `flagarize:"arg=name|help=Name."`
+			Required string   `flagarize:"arg=required|help=Required.|required=true"`
+			Short    string   `flagarize:"arg=short|help=Short.|short=s"`
+			Both     string   `flagarize:"arg=both|name=both|help=Both."`
+		}
+		err := flagarize.Flagarize(newTestKingpin(t), &config{})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: 4 error(s) occurred:\n"+
+			"* config.Name: positional argument \"name\" cannot follow variadic argument \"file\"; variadic argument has to be the last one\n"+
+			"* config.Required: positional argument \"required\" cannot follow variadic argument \"file\"; variadic argument has to be the last one\n"+
+			"* config.Short: key \"short\" is not supported for positional argument \"short\" of field \"Short\"; only [arg help required default envvar secret] are supported\n"+
+			"* config.Both: key \"name\" is not supported for positional argument \"both\" of field \"Both\"; only [arg help required default envvar secret] are supported", err.Error())
+	})
+}
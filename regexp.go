@@ -5,6 +5,7 @@ package flagarize
 
 import (
 	"regexp"
+	"strings"
 )
 
 type Regexp struct {
@@ -21,6 +22,14 @@ func (r *Regexp) Set(v string) (err error) {
 	return nil
 }
 
+// FormatValue returns the source text of the Regexp.
+func (r *Regexp) FormatValue() string {
+	if r.Regexp == nil {
+		return ""
+	}
+	return r.Regexp.String()
+}
+
 type AnchoredRegexp struct {
 	*regexp.Regexp
 }
@@ -34,3 +43,11 @@ func (r *AnchoredRegexp) Set(v string) (err error) {
 	r.Regexp = rg
 	return nil
 }
+
+// FormatValue returns the source text of the AnchoredRegexp without added anchors.
+func (r *AnchoredRegexp) FormatValue() string {
+	if r.Regexp == nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(r.Regexp.String(), "^(?:"), ")$")
+}
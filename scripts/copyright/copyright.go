@@ -32,6 +32,9 @@ func applyLicenseToProtoAndGo() error {
 			case "vendor":
 				return filepath.SkipDir
 			}
+			if info.Name() == "testdata" {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		if strings.HasSuffix(path, ".pb.go") {
@@ -46,6 +49,9 @@ func applyLicenseToProtoAndGo() error {
 			return err
 		}
 
+		if strings.HasPrefix(string(b), "// Code generated") {
+			return nil
+		}
 		if !strings.HasPrefix(string(b), string(license)) {
 			log.Println("file", path, "is missing Copyright header. Adding.")
 
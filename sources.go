@@ -0,0 +1,236 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// ValueSource is a source of the flag value.
+type ValueSource string
+
+const (
+	// SourceFlag means value was passed in command line.
+	SourceFlag ValueSource = "flag"
+	// SourceEnv means value was taken from environment variable.
+	SourceEnv ValueSource = "env"
+	// SourceCustom means value was looked up in the custom Source (see WithSources).
+	SourceCustom ValueSource = "source"
+	// SourceConfigFile means value was loaded from the config file (see WithConfigFile).
+	SourceConfigFile ValueSource = "file"
+	// SourceDefault means value is the default one, so from `default` struct tag, field value (see WithValuesAsDefaults)
+	// or zero value.
+	SourceDefault ValueSource = "default"
+)
+
+// sourcePriority orders sources from the highest priority.
+var sourcePriority = []ValueSource{SourceFlag, SourceEnv, SourceCustom, SourceConfigFile, SourceDefault}
+
+// SourceValue is raw input of the flag value from the given source.
+type SourceValue struct {
+	Source ValueSource
+	// Raw is raw string input. It has many elements for repeatable flags (slices and maps).
+	Raw []string
+}
+
+// FieldSource describes where value of the flagarized field came from.
+type FieldSource struct {
+	Path FieldPath
+	// Flag is name of the flag registered for the field. For custom Flagarizers registering many flags, it's the flag
+	// which value has the highest priority.
+	Flag string
+
+	SourceValue
+
+	// Overridden are lower priority sources that also had value for this field, from the highest priority.
+	Overridden []SourceValue
+}
+
+// sourceField is a field which value sources are tracked.
+type sourceField struct {
+	path  FieldPath
+	flags []*kingpin.FlagClause
+}
+
+// sourceTracker tracks sources of values of fields from the single flagarized struct.
+type sourceTracker struct {
+	mtx     sync.Mutex
+	parsed  bool
+	sources map[string]FieldSource
+	err     error
+}
+
+// install registers post-parse action that records sources of given fields. If registry does not allow registering
+// actions, the error is returned by Sources.
+func (t *sourceTracker) install(r KingpinRegistry, fields []sourceField, es elementSources) {
+	if len(fields) == 0 {
+		return
+	}
+	if err := addPostParseAction(r, func(ctx *kingpin.ParseContext) error {
+		t.mtx.Lock()
+		defer t.mtx.Unlock()
+
+		t.sources = make(map[string]FieldSource, len(fields))
+		for _, f := range fields {
+			t.sources[f.path.String()] = fieldSource(ctx, f, es)
+		}
+		t.parsed = true
+		return nil
+	}); err != nil {
+		t.err = errors.Wrap(err, "track sources")
+	}
+}
+
+// get returns copy of sources recorded on the last parse.
+func (t *sourceTracker) get() (map[string]FieldSource, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.err != nil {
+		return nil, errors.Wrap(t.err, "flagarize")
+	}
+	if !t.parsed {
+		return nil, errors.New("flagarize: flags were not parsed yet")
+	}
+	ret := make(map[string]FieldSource, len(t.sources))
+	for k, v := range t.sources {
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+func fieldSource(ctx *kingpin.ParseContext, field sourceField, es elementSources) FieldSource {
+	var fs FieldSource
+	best := len(sourcePriority)
+	for _, f := range field.flags {
+		values := flagSourceValues(ctx, f, es)
+		if len(values) == 0 {
+			continue
+		}
+		if p := sourceIndex(values[0].Source); p < best {
+			best = p
+			fs = FieldSource{Flag: f.Model().Name, SourceValue: values[0], Overridden: values[1:]}
+		}
+	}
+	fs.Path = field.path
+	return fs
+}
+
+func sourceIndex(s ValueSource) int {
+	for i, p := range sourcePriority {
+		if p == s {
+			return i
+		}
+	}
+	return len(sourcePriority)
+}
+
+// flagSourceValues returns values of the flag from all sources, from the highest priority.
+func flagSourceValues(ctx *kingpin.ParseContext, f *kingpin.FlagClause, es elementSources) []SourceValue {
+	var (
+		ret    []SourceValue
+		raw    []string
+		marked = map[ValueSource][]string{}
+	)
+	for _, e := range ctx.Elements {
+		if e.Clause != f || e.Value == nil {
+			continue
+		}
+		if s, ok := es.of(e); ok {
+			marked[s.source] = append(marked[s.source], *e.Value)
+			continue
+		}
+		raw = append(raw, *e.Value)
+	}
+	if len(raw) > 0 {
+		ret = append(ret, SourceValue{Source: SourceFlag, Raw: raw})
+	}
+	if f.HasEnvarValue() {
+		raw = []string{f.GetEnvarValue()}
+		if v, ok := f.Model().Value.(interface{ IsCumulative() bool }); ok && v.IsCumulative() {
+			raw = f.GetSplitEnvarValue()
+		}
+		ret = append(ret, SourceValue{Source: SourceEnv, Raw: raw})
+	}
+	for _, p := range sourcePriority {
+		if raw := marked[p]; len(raw) > 0 {
+			ret = append(ret, SourceValue{Source: p, Raw: raw})
+		}
+	}
+	return append(ret, SourceValue{Source: SourceDefault, Raw: f.Model().Default})
+}
+
+// elementSource is a source of the value added to the parse context as if it was set by flag.
+type elementSource struct {
+	source ValueSource
+	// location is a location of the value in the source e.g path to the file with line and column or name of the
+	// custom Source.
+	location string
+}
+
+// describe returns description of the source of the flag value e.g for errors.
+func (s elementSource) describe(f *kingpin.FlagClause) string {
+	switch s.source {
+	case SourceEnv:
+		return fmt.Sprintf("environment variable %s from %s (flag --%s)", f.Model().Envar, s.location, f.Model().Name)
+	case SourceCustom:
+		return fmt.Sprintf("source %s (flag --%s)", s.location, f.Model().Name)
+	}
+	return fmt.Sprintf("config file %s (flag --%s)", s.location, f.Model().Name)
+}
+
+// sourceElements are parse elements added by a single source (e.g config file) for values that do not come from the
+// command line, with their sources. Source resets them on each parse, so only elements of the last parse are kept.
+type sourceElements map[*kingpin.ParseElement]elementSource
+
+// add adds element with the value of the flag from the given source to the parse context, so the flag is considered
+// as set e.g by the required flags check and constraints.
+func (s sourceElements) add(ctx *kingpin.ParseContext, f *kingpin.FlagClause, value string, src elementSource) {
+	e := &kingpin.ParseElement{Clause: f, Value: &value}
+	ctx.Elements = append(ctx.Elements, e)
+	s[e] = src
+}
+
+// reset forgets elements added on the previous parse.
+func (s sourceElements) reset() {
+	for e := range s {
+		delete(s, e)
+	}
+}
+
+// installedElementSources returns elements of all sources installed in opts.
+func (o opts) installedElementSources() elementSources {
+	var es elementSources
+	if o.envFile != nil {
+		es = append(es, o.envFile.elements)
+	}
+	if o.lookup != nil {
+		es = append(es, o.lookup.elements)
+	}
+	if o.configFile != nil {
+		es = append(es, o.configFile.elements)
+	}
+	return es
+}
+
+// elementSources are elements added by all sources installed by a single Flagarize invocation.
+type elementSources []sourceElements
+
+// of returns source of the element, if it was added by any of sources.
+func (es elementSources) of(e *kingpin.ParseElement) (elementSource, bool) {
+	for _, s := range es {
+		if src, ok := s[e]; ok {
+			return src, true
+		}
+	}
+	return elementSource{}, false
+}
+
+// Sources returns sources of values of all flagarized fields by field path in dot notation (see FieldPath.String).
+// Sources are known only after successful parse.
+func (f *Flagarized) Sources() (map[string]FieldSource, error) { return f.sources.get() }
@@ -0,0 +1,91 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+func TestSources(t *testing.T) {
+	type webConfig struct {
+		Timeout time.Duration `flagarize:"name=timeout|help=Timeout.|default=1m|envvar=TIMEOUT"`
+		Address string        `flagarize:"name=address|help=Address.|envvar=ADDRESS"`
+	}
+	type config struct {
+		Web    webConfig                `flagarize:"prefix=web.|envprefix=WEB_"`
+		Since  flagarize.TimeOrDuration `flagarize:"name=since|help=Since.|default=1h"`
+		Labels []string                 `flagarize:"name=label|help=Labels.|envvar=LABELS"`
+	}
+
+	testutil.Ok(t, os.Setenv("WEB_TIMEOUT", "5m"))
+	testutil.Ok(t, os.Setenv("WEB_ADDRESS", "localhost:80"))
+	testutil.Ok(t, os.Setenv("LABELS", "a\nb"))
+	defer func() {
+		testutil.Ok(t, os.Unsetenv("WEB_TIMEOUT"))
+		testutil.Ok(t, os.Unsetenv("WEB_ADDRESS"))
+		testutil.Ok(t, os.Unsetenv("LABELS"))
+	}()
+
+	app := newTestKingpin(t)
+	cfg := &config{}
+	f, err := flagarize.New(app, cfg)
+	testutil.Ok(t, err)
+
+	_, err = f.Sources()
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "flagarize: flags were not parsed yet", err.Error())
+
+	_, err = app.Parse([]string{"--web.timeout=10s", "--since=-2h"})
+	testutil.Ok(t, err)
+
+	sources, err := f.Sources()
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string]flagarize.FieldSource{
+		"Web.Timeout": {
+			Path:        flagarize.FieldPath{"Web", "Timeout"},
+			Flag:        "web.timeout",
+			SourceValue: flagarize.SourceValue{Source: flagarize.SourceFlag, Raw: []string{"10s"}},
+			Overridden: []flagarize.SourceValue{
+				{Source: flagarize.SourceEnv, Raw: []string{"5m"}},
+				{Source: flagarize.SourceDefault, Raw: []string{"1m"}},
+			},
+		},
+		"Web.Address": {
+			Path:        flagarize.FieldPath{"Web", "Address"},
+			Flag:        "web.address",
+			SourceValue: flagarize.SourceValue{Source: flagarize.SourceEnv, Raw: []string{"localhost:80"}},
+			Overridden:  []flagarize.SourceValue{{Source: flagarize.SourceDefault}},
+		},
+		"Since": {
+			Path:        flagarize.FieldPath{"Since"},
+			Flag:        "since",
+			SourceValue: flagarize.SourceValue{Source: flagarize.SourceFlag, Raw: []string{"-2h"}},
+			Overridden:  []flagarize.SourceValue{{Source: flagarize.SourceDefault, Raw: []string{"1h"}}},
+		},
+		"Labels": {
+			Path:        flagarize.FieldPath{"Labels"},
+			Flag:        "label",
+			SourceValue: flagarize.SourceValue{Source: flagarize.SourceEnv, Raw: []string{"a", "b"}},
+			Overridden:  []flagarize.SourceValue{{Source: flagarize.SourceDefault}},
+		},
+	}, sources)
+
+	// Sources are tracked per flagarized struct, so the other struct in the other app does not affect them.
+	other, err := flagarize.New(newTestKingpin(t), &config{})
+	testutil.Ok(t, err)
+	_, err = other.Sources()
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "flagarize: flags were not parsed yet", err.Error())
+
+	_, err = app.Parse([]string{"--since=1h"})
+	testutil.Ok(t, err)
+	sources, err = f.Sources()
+	testutil.Ok(t, err)
+	testutil.Equals(t, flagarize.SourceEnv, sources["Web.Timeout"].Source)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import "reflect"
+
+// StructTags parses `flagarize:"..."` struct tags of struct fields the same way Flagarize does, without registering
+// anything. It allows tools to check or process tags of structs they know only from source (e.g flagarize/gen).
+// Zero value parses tags of fields of the flagarized struct, with the default "|" elements separator.
+type StructTags struct {
+	parent *nestedTag
+	path   FieldPath
+}
+
+// NestedStructTag is parsed struct tag of the nested struct field.
+type NestedStructTag struct {
+	// Command is the name of the command registered from the nested struct (see cmd struct tag key), empty if none.
+	Command     string
+	CommandHelp string
+	Hidden      bool
+	// Required is true if flags of the nested struct are required together (required=true).
+	Required bool
+
+	// Fields parses struct tags of fields of the nested struct.
+	Fields *StructTags
+}
+
+func (s *StructTags) nested() *nestedTag {
+	if s.parent == nil {
+		return &nestedTag{}
+	}
+	return s.parent
+}
+
+// Nested parses struct tag of the struct field as nested struct tag. It returns nil if field has struct tag of the
+// flag (or positional argument) instead. Fields parses tags of fields of the struct without struct tag as well.
+func (s *StructTags) Nested(field reflect.StructField) (*NestedStructTag, error) {
+	parent := s.nested()
+	path := s.path.Child(field.Name)
+	n, ok, err := parseNestedTag(field, parent, path, "|")
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &NestedStructTag{
+		Command:     n.cmd,
+		CommandHelp: n.cmdHelp,
+		Hidden:      n.hidden,
+		Required:    n.ownRequiredTogether != nil,
+		Fields:      &StructTags{parent: n, path: path},
+	}, nil
+}
+
+// Field parses struct tag of the field registered as flag (or positional argument), inheriting options of parent
+// nested struct tags. Help is looked up using lookupHelp (if not nil) when no help=<help> was specified. It returns
+// nil if field has no flagarize struct tag.
+func (s *StructTags) Field(field reflect.StructField, lookupHelp func(*Tag) *string) (*Tag, error) {
+	return parseTag(field, s.nested(), lookupHelp, "|")
+}
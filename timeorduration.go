@@ -9,36 +9,11 @@
 package flagarize
 
 import (
-	"bytes"
-	"fmt"
 	"time"
 
 	"github.com/bwplotka/flagarize/internal/timestamp"
 )
 
-// multiError is a slice of errors implementing the error interface. It is used
-// by a Gatherer to report multiple errors during MetricFamily gathering.
-type multiError []error
-
-func (errs multiError) Error() string {
-	if len(errs) == 0 {
-		return ""
-	}
-	buf := &bytes.Buffer{}
-	fmt.Fprintf(buf, "%d error(s) occurred:", len(errs))
-	for _, err := range errs {
-		fmt.Fprintf(buf, "\n* %s", err)
-	}
-	return buf.String()
-}
-
-// Append appends the provided error if it is not nil.
-func (errs *multiError) Append(err error) {
-	if err != nil {
-		*errs = append(*errs, err)
-	}
-}
-
 // TimeOrDuration is a custom kingping parser for time in RFC3339
 // or duration in Go's duration format, such as "300ms", "-1.5h" or "2h45m".
 // Only one will be set.
@@ -49,7 +24,7 @@ type TimeOrDuration struct {
 
 // Set converts string to TimeOrDuration.
 func (tdv *TimeOrDuration) Set(s string) error {
-	var merr multiError
+	var merr MultiError
 	t, err := time.Parse(time.RFC3339, s)
 	if err == nil {
 		tdv.Time = &t
@@ -88,6 +63,28 @@ func (tdv *TimeOrDuration) String() string {
 	return "nil"
 }
 
+// FormatValue returns either time in RFC3339 or duration, so the value accepted by Set.
+func (tdv *TimeOrDuration) FormatValue() string {
+	switch {
+	case tdv.Time != nil:
+		return tdv.Time.Format(time.RFC3339Nano)
+	case tdv.Dur != nil:
+		return tdv.Dur.String()
+	}
+	return ""
+}
+
+// Equal returns true if both represent the same time instant or the same duration.
+func (tdv *TimeOrDuration) Equal(o *TimeOrDuration) bool {
+	switch {
+	case tdv.Time != nil:
+		return o.Time != nil && tdv.Time.Equal(*o.Time)
+	case tdv.Dur != nil:
+		return o.Dur != nil && *tdv.Dur == *o.Dur
+	}
+	return o.Time == nil && o.Dur == nil
+}
+
 // PrometheusTimestamp returns TimeOrDuration converted to PrometheusTimestamp
 // if duration is set now+duration is converted to Timestamp.
 func (tdv *TimeOrDuration) PrometheusTimestamp() int64 {
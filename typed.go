@@ -0,0 +1,102 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Register allocates new T, registers flags based on its `flagarize:"..."` struct tags (see Flagarize) and returns it.
+// T has to be a struct. Fields are filled with values only after kingpin.Application.Parse is invoked.
+func Register[T any](r KingpinRegistry, o ...OptFunc) (*T, error) {
+	t := new(T)
+	if err := Flagarize(r, t, o...); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Value is an option registering fields of type T as flags parsed with the parse function, so T does not need to
+// implement ValueFlagarizer. The format function renders value the way it's passed as flag (see ValueFormatter),
+// fmt.Sprint is used if nil. It takes precedence over ValueFlagarizer and Flagarizer implemented by T. For example:
+//
+//	flagarize.Register[Config](a, flagarize.Value(level.Parse, level.Level.String))
+//
+// The same option has to be passed to other functions (e.g Dump or ToArgs), so values are rendered with format.
+func Value[T any](parse func(string) (T, error), format func(T) string) OptFunc {
+	return func(opt *opts) {
+		if opt.values == nil {
+			opt.values = map[reflect.Type]customValue{}
+		}
+		opt.values[reflect.TypeOf((*T)(nil)).Elem()] = valueFuncs[T]{parse: parse, format: format}
+	}
+}
+
+// customValue creates kingpin values for fields of the type registered with Value.
+type customValue interface {
+	newValue(fieldValue reflect.Value) kingpin.Value
+	formatValue(fieldValue reflect.Value) string
+}
+
+type valueFuncs[T any] struct {
+	parse  func(string) (T, error)
+	format func(T) string
+}
+
+func (f valueFuncs[T]) newValue(fieldValue reflect.Value) kingpin.Value {
+	return &typedValue[T]{valueFuncs: f, ptr: fieldValue.Addr().Interface().(*T)}
+}
+
+func (f valueFuncs[T]) formatValue(fieldValue reflect.Value) string {
+	v := fieldValue.Interface().(T)
+	if f.format == nil {
+		return fmt.Sprint(v)
+	}
+	return f.format(v)
+}
+
+// typedValue is kingpin.Value setting field of type T with parse function.
+type typedValue[T any] struct {
+	valueFuncs[T]
+	ptr *T
+}
+
+func (v *typedValue[T]) Set(s string) error {
+	p, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	*v.ptr = p
+	return nil
+}
+
+func (v *typedValue[T]) String() string { return v.formatValue(reflect.ValueOf(v.ptr).Elem()) }
+
+// customValue returns custom value registered with Value for the type, nil if none.
+func (o opts) customValue(t reflect.Type) customValue { return o.values[t] }
+
+// formatValue renders the field value the same way as formatValue, using format function of the custom value
+// registered with Value if any.
+func (o opts) formatValue(v reflect.Value) []string {
+	if !v.IsValid() || isZeroValue(v) {
+		return nil
+	}
+	if cv := o.customValue(v.Type()); cv != nil && v.CanInterface() {
+		return []string{cv.formatValue(v)}
+	}
+	return formatValue(v)
+}
+
+// registerCustomValue registers the field value with the custom value registered with Value, custom Flagarizer or
+// ValueFlagarizer if field type supports any of them. It returns false otherwise.
+func registerCustomValue(r KingpinRegistry, tag *Tag, fieldValue reflect.Value, o opts) (bool, error) {
+	if cv := o.customValue(fieldValue.Type()); cv != nil {
+		tag.Flag(r).SetValue(cv.newValue(fieldValue))
+		return true, nil
+	}
+	return invokeFlagarizersIfImplements(r, tag, fieldValue)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// typedEndpoint is a struct type without Set method, parsed with parseTypedEndpoint.
+type typedEndpoint struct {
+	Host string
+	Port int
+}
+
+func parseTypedEndpoint(s string) (typedEndpoint, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return typedEndpoint{}, errors.Errorf("missing port in %q", s)
+	}
+	port, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return typedEndpoint{}, err
+	}
+	return typedEndpoint{Host: s[:i], Port: port}, nil
+}
+
+func formatTypedEndpoint(e typedEndpoint) string { return fmt.Sprintf("%s:%d", e.Host, e.Port) }
+
+type typedConfig struct {
+	Listen   typedEndpoint `flagarize:"name=listen|help=Listen address.|default=localhost:80"`
+	Upstream typedEndpoint `flagarize:"arg=upstream|help=Upstream address."`
+	Name     string        `flagarize:"name=name|help=Name."`
+}
+
+func TestRegister(t *testing.T) {
+	value := flagarize.Value(parseTypedEndpoint, formatTypedEndpoint)
+
+	t.Run("parse", func(t *testing.T) {
+		app := kingpin.New("app", "")
+		cfg, err := flagarize.Register[typedConfig](app, value)
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"--name=a", "example.com:443"})
+		testutil.Ok(t, err)
+		testutil.Equals(t, typedConfig{
+			Listen:   typedEndpoint{Host: "localhost", Port: 80},
+			Upstream: typedEndpoint{Host: "example.com", Port: 443},
+			Name:     "a",
+		}, *cfg)
+
+		args, err := flagarize.ToArgs(cfg, value)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{"--listen=localhost:80", "--name=a", "example.com:443"}, args)
+
+		var b strings.Builder
+		testutil.Ok(t, flagarize.Dump(&b, cfg, flagarize.DumpArgs, value))
+		testutil.Equals(t, "--listen=localhost:80\nexample.com:443\n--name=a\n", b.String())
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		app := kingpin.New("app", "")
+		_, err := flagarize.Register[typedConfig](app, value)
+		testutil.Ok(t, err)
+
+		_, err = app.Parse([]string{"--listen=localhost"})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, `missing port in "localhost"`, err.Error())
+	})
+	t.Run("no custom value", func(t *testing.T) {
+		_, err := flagarize.Register[typedConfig](kingpin.New("app", ""))
+		testutil.NotOk(t, err)
+		testutil.Assert(t, strings.Contains(err.Error(), "flagarize struct Tag found on not supported type flagarize_test.typedEndpoint"), "unexpected error: %s", err)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := flagarize.Register[string](kingpin.New("app", ""))
+		testutil.NotOk(t, err)
+	})
+}
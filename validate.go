@@ -0,0 +1,68 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Validator can be implemented by any flagarized field type or struct (including the flagarized struct itself) to
+// validate its value after parse. See Validate and WithValidation.
+type Validator interface {
+	// Validate returns error if value is not valid.
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// WithValidation makes Flagarize register post-parse action that runs Validate on the flagarized struct.
+// It requires registry that allows registering actions (e.g *kingpin.Application or *kingpin.CmdClause).
+func WithValidation() OptFunc { return func(opt *opts) { opt.validate = true } }
+
+// Validate invokes Validate method on every flagarized field and nested struct that implements Validator (including
+// the given struct itself). Validation is done bottom-up: fields of nested structs are validated before the struct.
+// All failures are returned in one error, each annotated with field path and flag name (if any).
+// The same options as passed to Flagarize should be passed, so flag names are the same.
+func Validate(s interface{}, o ...OptFunc) error {
+	v, err := structValue(s)
+	if err != nil {
+		return err
+	}
+	return validate(v, opts{elemSep: "|"}.apply(o...))
+}
+
+func validate(value reflect.Value, o opts) error {
+	var merr MultiError
+	if err := walkStruct(value, nil, &nestedTag{}, o, func(f walkedField) error {
+		err := invokeValidator(f.value)
+		switch {
+		case err == nil:
+		case f.tag != nil:
+			merr.Append(errors.Wrapf(err, "%s (%s)", f.path, flagOrArg(f.tag)))
+		default:
+			merr.Append(errors.Wrapf(err, "%s", f.path))
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "flagarize")
+	}
+	merr.Append(invokeValidator(value))
+	return merr.Err()
+}
+
+// invokeValidator invokes Validate if value (or pointer to it) implements Validator. Nil pointers are not validated.
+func invokeValidator(v reflect.Value) error {
+	if !v.CanInterface() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(validatorType) {
+		return v.Addr().Interface().(Validator).Validate()
+	}
+	if v.Type().Implements(validatorType) {
+		return v.Interface().(Validator).Validate()
+	}
+	return nil
+}
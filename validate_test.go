@@ -0,0 +1,91 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+	"github.com/pkg/errors"
+)
+
+type port int
+
+func (p *port) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	*p = port(v)
+	return err
+}
+
+func (p port) Validate() error {
+	if p < 0 || p > 65535 {
+		return errors.Errorf("port %d out of range", p)
+	}
+	return nil
+}
+
+type listenConfig struct {
+	Host string `flagarize:"name=host|help=Host."`
+	Port port   `flagarize:"name=port|help=Port."`
+}
+
+func (c *listenConfig) Validate() error {
+	if c.Host == "" && c.Port != 0 {
+		return errors.New("port set without host")
+	}
+	return nil
+}
+
+type validatedConfig struct {
+	Listen listenConfig `flagarize:"prefix=listen."`
+	Debug  bool         `flagarize:"name=debug|help=Debug."`
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.Debug {
+		return errors.New("debug not allowed")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		testutil.Ok(t, flagarize.Validate(&validatedConfig{Listen: listenConfig{Host: "localhost", Port: 80}}))
+	})
+	t.Run("single failure", func(t *testing.T) {
+		err := flagarize.Validate(&validatedConfig{Debug: true})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "debug not allowed", err.Error())
+	})
+	t.Run("all failures bottom-up", func(t *testing.T) {
+		err := flagarize.Validate(&validatedConfig{Listen: listenConfig{Port: 70000}, Debug: true})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "3 error(s) occurred:\n"+
+			"* Listen.Port (--listen.port): port 70000 out of range\n"+
+			"* Listen: port set without host\n"+
+			"* debug not allowed", err.Error())
+	})
+	t.Run("not a pointer", func(t *testing.T) {
+		testutil.NotOk(t, flagarize.Validate(validatedConfig{}))
+	})
+}
+
+func TestFlagarize_WithValidation(t *testing.T) {
+	app := newTestKingpin(t)
+	cfg := &validatedConfig{}
+	testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithValidation()))
+
+	_, err := app.Parse([]string{"--listen.host=localhost", "--listen.port=80"})
+	testutil.Ok(t, err)
+
+	app = newTestKingpin(t)
+	cfg = &validatedConfig{}
+	testutil.Ok(t, flagarize.Flagarize(app, cfg, flagarize.WithValidation()))
+
+	_, err = app.Parse([]string{"--listen.port=80"})
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "Listen: port set without host", err.Error())
+}
@@ -0,0 +1,281 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	minStructTagKey      = "min"
+	maxStructTagKey      = "max"
+	minlenStructTagKey   = "minlen"
+	maxlenStructTagKey   = "maxlen"
+	patternStructTagKey  = "pattern"
+	nonemptyStructTagKey = "nonempty"
+	enumStructTagKey     = "enum"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// valueCheck checks value of the field registered as flag against value constraints from the struct tag.
+type valueCheck struct {
+	flag  *kingpin.FlagClause
+	value reflect.Value
+	// sources are elements added by sources installed together with the flag, used to describe source of the value.
+	sources elementSources
+
+	min, max       *bound
+	minLen, maxLen int
+	pattern        *regexp.Regexp
+	nonEmpty       bool
+	enum           []string
+
+	help string
+}
+
+// bound is min or max bound of numeric or duration value.
+type bound struct {
+	raw string
+	i   int64
+	u   uint64
+	f   float64
+}
+
+// newValueCheck returns valueCheck for value constraints from the tag or nil if there are none. Error is returned
+// if constraints are not supported for the field type or are malformed.
+func newValueCheck(tag *Tag, typ reflect.Type) (*valueCheck, error) {
+	if tag.Min == "" && tag.Max == "" && tag.MinLen == "" && tag.MaxLen == "" && tag.Pattern == "" && !tag.NonEmpty && len(tag.Enum) == 0 {
+		return nil, nil
+	}
+
+	c := &valueCheck{minLen: -1, maxLen: -1, nonEmpty: tag.NonEmpty, enum: tag.Enum}
+	elem := typ
+	if typ.Kind() == reflect.Slice {
+		elem = typ.Elem()
+	}
+
+	var err error
+	if tag.Min != "" {
+		if c.min, err = parseBound(tag.Min, elem); err != nil {
+			return nil, errors.Wrap(err, minStructTagKey)
+		}
+	}
+	if tag.Max != "" {
+		if c.max, err = parseBound(tag.Max, elem); err != nil {
+			return nil, errors.Wrap(err, maxStructTagKey)
+		}
+	}
+	if tag.MinLen != "" {
+		if c.minLen, err = parseLen(tag.MinLen, typ); err != nil {
+			return nil, errors.Wrap(err, minlenStructTagKey)
+		}
+	}
+	if tag.MaxLen != "" {
+		if c.maxLen, err = parseLen(tag.MaxLen, typ); err != nil {
+			return nil, errors.Wrap(err, maxlenStructTagKey)
+		}
+	}
+	if tag.Pattern != "" {
+		if elem.Kind() != reflect.String {
+			return nil, errors.Errorf("%s is not supported for type %s", patternStructTagKey, typ)
+		}
+		if c.pattern, err = regexp.Compile("^(?:" + tag.Pattern + ")$"); err != nil {
+			return nil, errors.Wrap(err, patternStructTagKey)
+		}
+	}
+	if tag.NonEmpty {
+		if k := typ.Kind(); k != reflect.String && k != reflect.Slice && k != reflect.Map {
+			return nil, errors.Errorf("%s is not supported for type %s", nonemptyStructTagKey, typ)
+		}
+	}
+	if len(tag.Enum) > 0 && elem.Kind() != reflect.String {
+		return nil, errors.Errorf("%s is not supported for type %s", enumStructTagKey, typ)
+	}
+	c.help = c.describe()
+	return c, nil
+}
+
+func parseBound(raw string, typ reflect.Type) (_ *bound, err error) {
+	b := &bound{raw: raw}
+	switch {
+	case typ == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(raw)
+		b.i = int64(d)
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		b.i, err = strconv.ParseInt(raw, 10, 64)
+	case typ.Kind() >= reflect.Uint && typ.Kind() <= reflect.Uint64:
+		b.u, err = strconv.ParseUint(raw, 10, 64)
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		b.f, err = strconv.ParseFloat(raw, 64)
+	default:
+		return nil, errors.Errorf("not supported for type %s", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func parseLen(raw string, typ reflect.Type) (int, error) {
+	if k := typ.Kind(); k != reflect.String && k != reflect.Slice && k != reflect.Map {
+		return 0, errors.Errorf("not supported for type %s", typ)
+	}
+	l, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, err
+	}
+	if l < 0 {
+		return 0, errors.Errorf("length cannot be negative, got %d", l)
+	}
+	return l, nil
+}
+
+// describe returns constraints in the form shown in help e.g "(1..65535)".
+func (c *valueCheck) describe() string {
+	var d []string
+	switch {
+	case c.min != nil && c.max != nil:
+		d = append(d, fmt.Sprintf("(%s..%s)", c.min.raw, c.max.raw))
+	case c.min != nil:
+		d = append(d, fmt.Sprintf("(>=%s)", c.min.raw))
+	case c.max != nil:
+		d = append(d, fmt.Sprintf("(<=%s)", c.max.raw))
+	}
+	switch {
+	case c.minLen >= 0 && c.maxLen >= 0:
+		d = append(d, fmt.Sprintf("(length %d..%d)", c.minLen, c.maxLen))
+	case c.minLen >= 0:
+		d = append(d, fmt.Sprintf("(length >=%d)", c.minLen))
+	case c.maxLen >= 0:
+		d = append(d, fmt.Sprintf("(length <=%d)", c.maxLen))
+	}
+	if c.nonEmpty {
+		d = append(d, "(non-empty)")
+	}
+	if c.pattern != nil {
+		d = append(d, fmt.Sprintf("(matching %s)", strings.TrimSuffix(strings.TrimPrefix(c.pattern.String(), "^(?:"), ")$")))
+	}
+	if len(c.enum) > 0 {
+		d = append(d, fmt.Sprintf("(one of: %s)", strings.Join(c.enum, ", ")))
+	}
+	return strings.Join(d, " ")
+}
+
+// check returns error if the parsed value violates constraints. Error names source of the value.
+func (c *valueCheck) check(ctx *kingpin.ParseContext) error {
+	if err := c.checkValue(c.value); err != nil {
+		v := formatValue(c.value)
+		if len(v) == 0 {
+			v = formatZeroValue(c.value)
+		}
+		return errors.Wrapf(err, "invalid value %q from %s", strings.Join(v, ","), flagSource(ctx, c.flag, c.sources))
+	}
+	return nil
+}
+
+func (c *valueCheck) checkValue(v reflect.Value) error {
+	if k := v.Kind(); k == reflect.String || k == reflect.Slice || k == reflect.Map {
+		if c.nonEmpty && v.Len() == 0 {
+			return errors.New("has to be non-empty")
+		}
+		if c.minLen >= 0 && v.Len() < c.minLen {
+			return errors.Errorf("length has to be at least %d", c.minLen)
+		}
+		if c.maxLen >= 0 && v.Len() > c.maxLen {
+			return errors.Errorf("length has to be at most %d", c.maxLen)
+		}
+	}
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if err := c.checkElem(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.checkElem(v)
+}
+
+func (c *valueCheck) checkElem(v reflect.Value) error {
+	if c.min != nil && c.min.compare(v) < 0 {
+		return errors.Errorf("has to be greater than or equal to %s", c.min.raw)
+	}
+	if c.max != nil && c.max.compare(v) > 0 {
+		return errors.Errorf("has to be less than or equal to %s", c.max.raw)
+	}
+	if v.Kind() != reflect.String || v.Len() == 0 {
+		// Empty strings are checked only by nonempty.
+		return nil
+	}
+	if c.pattern != nil && !c.pattern.MatchString(v.String()) {
+		return errors.Errorf("has to match %s", c.pattern)
+	}
+	if len(c.enum) > 0 {
+		for _, e := range c.enum {
+			if e == v.String() {
+				return nil
+			}
+		}
+		return errors.Errorf("has to be one of: %s", strings.Join(c.enum, ", "))
+	}
+	return nil
+}
+
+// compare returns -1, 0 or 1 if value is lower, equal or greater than the bound.
+func (b *bound) compare(v reflect.Value) int {
+	switch {
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		return compare(v.Int() < b.i, v.Int() > b.i)
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		return compare(v.Uint() < b.u, v.Uint() > b.u)
+	default:
+		return compare(v.Float() < b.f, v.Float() > b.f)
+	}
+}
+
+func compare(lower, greater bool) int {
+	if lower {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
+
+// flagSource returns description of the source of the flag value.
+func flagSource(ctx *kingpin.ParseContext, f *kingpin.FlagClause, es elementSources) string {
+	var marked []elementSource
+	for _, e := range ctx.Elements {
+		if e.Clause != f {
+			continue
+		}
+		s, ok := es.of(e)
+		if !ok {
+			return fmt.Sprintf("flag --%s", f.Model().Name)
+		}
+		marked = append(marked, s)
+	}
+	if f.HasEnvarValue() {
+		return fmt.Sprintf("environment variable %s (flag --%s)", f.Model().Envar, f.Model().Name)
+	}
+	for _, p := range sourcePriority {
+		for _, s := range marked {
+			if s.source == p {
+				return s.describe(f)
+			}
+		}
+	}
+	return fmt.Sprintf("default of flag --%s", f.Model().Name)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/flagarize"
+	"github.com/bwplotka/flagarize/testutil"
+)
+
+type boundedConfig struct {
+	Port    int               `flagarize:"name=port|help=Port.|min=1|max=65535|default=80|envvar=PORT"`
+	Timeout time.Duration     `flagarize:"name=timeout|help=Timeout.|min=1s|default=5s"`
+	Ratio   float64           `flagarize:"name=ratio|help=Ratio.|max=1"`
+	Name    string            `flagarize:"name=name|help=Name.|pattern=[a-z]+(-[a-z]+)*|maxlen=10"`
+	Mode    string            `flagarize:"name=mode|help=Mode.|enum=fast|enum=slow|default=fast"`
+	Peers   []string          `flagarize:"name=peer|help=Peers.|minlen=1|maxlen=3"`
+	Labels  map[string]string `flagarize:"name=label|help=Labels.|nonempty=true|default=a=b"`
+}
+
+func TestFlagarize_ValueConstraints(t *testing.T) {
+	t.Run("help", func(t *testing.T) {
+		app := newTestKingpin(t)
+		testutil.Ok(t, flagarize.Flagarize(app, &boundedConfig{}))
+
+		for name, help := range map[string]string{
+			"port":    "Port. (1..65535)",
+			"timeout": "Timeout. (>=1s)",
+			"ratio":   "Ratio. (<=1)",
+			"name":    "Name. (length <=10) (matching [a-z]+(-[a-z]+)*)",
+			"mode":    "Mode. (one of: fast, slow)",
+			"peer":    "Peers. (length 1..3)",
+			"label":   "Labels. (non-empty)",
+		} {
+			testutil.Equals(t, help, app.GetFlag(name).Model().Help)
+		}
+	})
+	for _, tcase := range []struct {
+		input       []string
+		env         map[string]string
+		expectedErr string
+	}{
+		{input: []string{"--peer=a", "--name=some-name"}},
+		{
+			input:       []string{"--peer=a", "--port=70000"},
+			expectedErr: "invalid value \"70000\" from flag --port: has to be less than or equal to 65535",
+		},
+		{
+			input:       []string{"--peer=a"},
+			env:         map[string]string{"PORT": "0"},
+			expectedErr: "invalid value \"0\" from environment variable PORT (flag --port): has to be greater than or equal to 1",
+		},
+		{
+			input:       []string{},
+			expectedErr: "invalid value \"\" from default of flag --peer: length has to be at least 1",
+		},
+		{
+			input: []string{"--peer=a", "--timeout=1ms", "--ratio=1.5", "--name=Some", "--mode=medium", "--label=a=b", "--label=c=d"},
+			expectedErr: "4 error(s) occurred:\n" +
+				"* invalid value \"1ms\" from flag --timeout: has to be greater than or equal to 1s\n" +
+				"* invalid value \"1.5\" from flag --ratio: has to be less than or equal to 1\n" +
+				"* invalid value \"Some\" from flag --name: has to match ^(?:[a-z]+(-[a-z]+)*)$\n" +
+				"* invalid value \"medium\" from flag --mode: has to be one of: fast, slow",
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			for k, v := range tcase.env {
+				testutil.Ok(t, os.Setenv(k, v))
+				defer func(k string) { testutil.Ok(t, os.Unsetenv(k)) }(k)
+			}
+
+			app := newTestKingpin(t)
+			testutil.Ok(t, flagarize.Flagarize(app, &boundedConfig{}))
+			_, err := app.Parse(tcase.input)
+			if tcase.expectedErr != "" {
+				testutil.NotOk(t, err)
+				testutil.Equals(t, tcase.expectedErr, err.Error())
+				return
+			}
+			testutil.Ok(t, err)
+		})
+	}
+	t.Run("wrong constraints", func(t *testing.T) {
+		type wrongMin struct {
+			F int `flagarize:"name=f|help=F.|min=a"`
+		}
+		err := flagarize.Flagarize(newTestKingpin(t), &wrongMin{})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: wrongMin.F: min: strconv.ParseInt: parsing \"a\": invalid syntax", err.Error())
+
+		type wrongType struct {
+			F bool `flagarize:"name=f|help=F.|nonempty=true"`
+		}
+		err = flagarize.Flagarize(newTestKingpin(t), &wrongType{})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "flagarize: wrongType.F: nonempty is not supported for type bool", err.Error())
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package flagarize
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// walkedField is a field visited by walkStruct.
+type walkedField struct {
+	path  FieldPath
+	field reflect.StructField
+	value reflect.Value
+	// tag is parsed struct tag of the flagarized field. It is nil for nested structs.
+	tag *Tag
+}
+
+// structValue returns struct value of the pointer to struct passed to the API functions.
+func structValue(s interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Ptr {
+		return reflect.Value{}, errors.New("flagarize: object must be a pointer to struct or interface")
+	}
+	if v.IsNil() {
+		return reflect.Value{}, errors.New("flagarize: object cannot be nil")
+	}
+	if v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("flagarize: object must be a pointer to struct or interface, got: %s", v.Elem())
+	}
+	return v.Elem(), nil
+}
+
+// walkStruct visits the same fields Flagarize registers flags for, in the declaration order. Nested structs are
+// visited after their fields. Help is not resolved, so tags can be parsed without help.
+func walkStruct(value reflect.Value, path FieldPath, parent *nestedTag, o opts, visit func(walkedField) error) error {
+	noHelp := ""
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+		fieldPath := path.Child(field.Name)
+		if isIgnored(field) || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		nested := parent
+		if field.Type.Kind() == reflect.Struct && !implementsFlagarizer(field.Type) && !implementsValueFlagarizer(field.Type) && o.customValue(field.Type) == nil {
+			n, ok, err := parseNestedTag(field, parent, fieldPath, o.elemSep)
+			if err != nil {
+				return errors.Wrap(err, "parse flagarize tags")
+			}
+			if ok {
+				if n.cmd != "" && o.commandSelected != nil && !o.commandSelected(fieldPath) {
+					continue
+				}
+				nested = n
+			}
+		}
+		if nested == parent {
+			tag, err := parseTag(field, parent, func(*Tag) *string { return &noHelp }, o.elemSep)
+			if err != nil {
+				return errors.Wrap(err, "parse flagarize tags")
+			}
+			if tag != nil {
+				if err := visit(walkedField{path: fieldPath, field: field, value: fieldValue, tag: tag}); err != nil {
+					return err
+				}
+				continue
+			}
+			if fieldValue.Kind() != reflect.Struct {
+				continue
+			}
+		}
+
+		if err := walkStruct(fieldValue, fieldPath, nested, o, visit); err != nil {
+			return err
+		}
+		if err := visit(walkedField{path: fieldPath, field: field, value: fieldValue}); err != nil {
+			return err
+		}
+	}
+	return nil
+}